@@ -0,0 +1,216 @@
+package jsh
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+const (
+	// AtomicExtension is the URI of the Atomic Operations extension:
+	// http://jsonapi.org/ext/atomic/
+	AtomicExtension = "https://jsonapi.org/ext/atomic"
+	// OperationAdd creates a resource or adds members to a to-many relationship
+	OperationAdd = "add"
+	// OperationUpdate updates a resource or replaces a relationship
+	OperationUpdate = "update"
+	// OperationRemove deletes a resource or removes members from a to-many relationship
+	OperationRemove = "remove"
+	// operationsMember is the top-level member of an atomic operations request document
+	operationsMember = "atomic:operations"
+)
+
+/*
+Operation is an operation of an Atomic Operations request document:
+http://jsonapi.org/ext/atomic/#operation-objects
+
+Data contains the resource object or the resource linkage of the operation. Both a
+missing and a null data member are decoded as a nil Data.
+*/
+type Operation struct {
+	Op   string                 `json:"op"`
+	Ref  *OperationRef          `json:"ref,omitempty"`
+	Href string                 `json:"href,omitempty"`
+	Data List                   `json:"data,omitempty"`
+	Meta map[string]interface{} `json:"meta,omitempty"`
+}
+
+// OperationRef references the target resource or relationship of an operation.
+// The resource is identified either by ID or by a local ID (LID) set by an earlier
+// operation of the same request.
+type OperationRef struct {
+	Type         string `json:"type"`
+	ID           string `json:"id,omitempty"`
+	LID          string `json:"lid,omitempty"`
+	Relationship string `json:"relationship,omitempty"`
+}
+
+// IsRelationship returns true if the operation targets a relationship rather than a resource.
+func (op *Operation) IsRelationship() bool {
+	return op.Ref != nil && op.Ref.Relationship != ""
+}
+
+// Object returns the resource object of the operation data, or nil if there is none.
+func (op *Operation) Object() *Object {
+	if len(op.Data) == 0 {
+		return nil
+	}
+	return op.Data[0]
+}
+
+// Linkage returns the resource linkage of a relationship operation data.
+func (op *Operation) Linkage() IDList {
+	linkage := IDList{}
+	for _, object := range op.Data {
+		linkage = append(linkage, object.ToIDObject())
+	}
+	return linkage
+}
+
+// validate checks that the operation at the given index follows the Atomic Operations extension.
+func (op *Operation) validate(index int) *Error {
+	switch op.Op {
+	case OperationAdd, OperationUpdate, OperationRemove:
+	default:
+		return operationError(index, "/op", fmt.Sprintf("Unknown operation code '%s'", op.Op))
+	}
+	if op.Ref != nil && op.Href != "" {
+		return operationError(index, "", "Operation cannot have both 'ref' and 'href'")
+	}
+	if op.Ref != nil {
+		if op.Ref.Type == "" {
+			return operationError(index, "/ref/type", "Missing operation target type")
+		}
+		if (op.Ref.ID == "") == (op.Ref.LID == "") {
+			return operationError(index, "/ref", "Operation target must have exactly one of 'id' or 'lid'")
+		}
+	}
+
+	switch {
+	case op.IsRelationship():
+		if op.Op != OperationUpdate && len(op.Data) == 0 {
+			return operationError(index, "/data", "Missing relationship data")
+		}
+	case op.Op == OperationRemove:
+		if op.Ref == nil && op.Href == "" {
+			return operationError(index, "", "Remove operation must have a 'ref' or 'href'")
+		}
+	case op.Op == OperationAdd && op.Ref != nil:
+		return operationError(index, "/ref", "Add operation can only target a relationship")
+	default:
+		if len(op.Data) != 1 {
+			return operationError(index, "/data", "Operation data must be a single resource object")
+		}
+		if op.Op == OperationUpdate && op.Ref == nil && op.Href == "" && op.Object().ID == "" {
+			return operationError(index, "/data/id", "Missing target resource ID")
+		}
+	}
+
+	for _, object := range op.Data {
+		if errlist := validateInput(object); errlist != nil {
+			return OperationError(index, errlist[0])
+		}
+		if errlist := validateRelationships(object); errlist != nil {
+			return OperationError(index, errlist[0])
+		}
+	}
+	return nil
+}
+
+/*
+ParseOperations validates the HTTP request and returns the operations of an Atomic
+Operations request document. The request Content-Type must apply the Atomic Operations
+extension, which must be registered with RegisterExtension(jsh.AtomicExtension).
+
+	ops, err := jsh.ParseOperations(r)
+	if err != nil {
+		jsh.Send(w, r, err)
+		return
+	}
+
+	results := jsh.AtomicResults{}
+	for i, op := range ops {
+		object, err := apply(op)
+		if err != nil {
+			jsh.Send(w, r, jsh.OperationError(i, err))
+			return
+		}
+		results = append(results, &jsh.AtomicResult{Data: object})
+	}
+
+	jsh.Send(w, r, results)
+*/
+func ParseOperations(r *http.Request) ([]*Operation, *Error) {
+	document, err := ParseDoc(r, AtomicMode)
+	if err != nil {
+		return nil, err
+	}
+	return document.Operations, nil
+}
+
+// validateOperations validates the operations of an atomic operations request document.
+func (d *Document) validateOperations() *Error {
+	if d.HasData() {
+		return BadRequestError("Invalid atomic operations document", "Primary data is not allowed")
+	}
+	if len(d.Operations) == 0 {
+		return TopLevelError(operationsMember)
+	}
+	for i, op := range d.Operations {
+		if err := op.validate(i); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AtomicResult is the result of an operation: http://jsonapi.org/ext/atomic/#result-objects
+type AtomicResult struct {
+	Data *Object                `json:"data,omitempty"`
+	Meta map[string]interface{} `json:"meta,omitempty"`
+}
+
+// AtomicResults is the list of operation results of an Atomic Operations response
+// document. It implements Sendable, results must be in the order of the operations.
+type AtomicResults []*AtomicResult
+
+// Validate ensures that the results are JSON API compatible.
+func (results AtomicResults) Validate(r *http.Request, response bool) *Error {
+	for _, result := range results {
+		if result == nil || result.Data == nil {
+			continue
+		}
+		if err := result.Data.Validate(r, response); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// OperationPointer returns a JSON pointer to the given member of the operation at index.
+func OperationPointer(index int, pointer string) string {
+	return fmt.Sprintf("/%s/%d%s", operationsMember, index, pointer)
+}
+
+/*
+OperationError makes the source pointer of the error relative to the operation at index.
+Pointers such as "/data/attributes/title" produced by InputError or RelationshipError
+become "/atomic:operations/<index>/data/attributes/title".
+*/
+func OperationError(index int, err *Error) *Error {
+	if err.Source == nil || err.Source.Pointer == "" {
+		return err
+	}
+	if !strings.HasPrefix(err.Source.Pointer, "/"+operationsMember+"/") {
+		err.Source.Pointer = OperationPointer(index, err.Source.Pointer)
+	}
+	return err
+}
+
+// operationError returns a 400 error pointing to the given member of the operation at index.
+func operationError(index int, pointer, detail string) *Error {
+	err := newBuiltinError(CodeInvalidOperation)
+	err.Detail = detail
+	err.Source = &ErrorSource{Pointer: OperationPointer(index, pointer)}
+	return err
+}
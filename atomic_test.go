@@ -0,0 +1,129 @@
+package jsh
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestAtomic(t *testing.T) {
+
+	Convey("Atomic Operations Tests", t, func() {
+
+		RegisterExtension(AtomicExtension)
+		Reset(func() {
+			supportedExtensions.remove(AtomicExtension)
+		})
+
+		atomicType := ContentType + `; ext="` + AtomicExtension + `"`
+
+		parse := func(body string) ([]*Operation, *Error) {
+			req, err := http.NewRequest("POST", "/operations", strings.NewReader(body))
+			So(err, ShouldBeNil)
+			req.Header.Set("Content-Type", atomicType)
+			return ParseOperations(req)
+		}
+
+		Convey("->ParseOperations()", func() {
+
+			Convey("should parse resource and relationship operations", func() {
+				ops, err := parse(`{"atomic:operations": [
+					{"op": "add", "data": {"type": "articles", "attributes": {"title": "JSON API"}}},
+					{"op": "update", "ref": {"type": "articles", "id": "1", "relationship": "tags"}, "data": [{"type": "tags", "id": "2"}]},
+					{"op": "update", "ref": {"type": "articles", "id": "1", "relationship": "author"}, "data": null},
+					{"op": "remove", "ref": {"type": "articles", "id": "1"}}
+				]}`)
+				So(err, ShouldBeNil)
+				So(ops, ShouldHaveLength, 4)
+				So(ops[0].Op, ShouldEqual, OperationAdd)
+				So(ops[0].Object().Type, ShouldEqual, "articles")
+				So(ops[1].IsRelationship(), ShouldBeTrue)
+				So(ops[1].Linkage(), ShouldResemble, IDList{NewIDObject("tags", "2")})
+				So(ops[2].Object(), ShouldBeNil)
+				So(ops[3].Ref.ID, ShouldEqual, "1")
+			})
+
+			Convey("should require the atomic extension in the Content-Type", func() {
+				req, err := http.NewRequest("POST", "/operations", strings.NewReader(`{"atomic:operations": []}`))
+				So(err, ShouldBeNil)
+				req.Header.Set("Content-Type", ContentType)
+
+				_, parseErr := ParseOperations(req)
+				So(parseErr, ShouldNotBeNil)
+				So(parseErr.Status, ShouldEqual, http.StatusUnsupportedMediaType)
+			})
+
+			Convey("should reject a document without operations", func() {
+				_, err := parse(`{"data": {"type": "articles", "id": "1"}}`)
+				So(err, ShouldNotBeNil)
+				So(err.Status, ShouldEqual, http.StatusBadRequest)
+
+				_, err = parse(`{"atomic:operations": []}`)
+				So(err, ShouldNotBeNil)
+				So(err.Status, ShouldEqual, 422)
+				So(err.Detail, ShouldContainSubstring, "atomic:operations")
+			})
+
+			Convey("should reject invalid operations with a pointer to the operation", func() {
+				invalid := []struct {
+					op      string
+					status  int
+					pointer string
+				}{
+					{`{"op": "copy", "data": {"type": "articles"}}`, 400, "/atomic:operations/1/op"},
+					{`{"op": "add", "ref": {"type": "articles", "id": "1"}, "data": {"type": "articles"}}`, 400, "/atomic:operations/1/ref"},
+					{`{"op": "remove", "ref": {"id": "1"}}`, 400, "/atomic:operations/1/ref/type"},
+					{`{"op": "remove", "ref": {"type": "articles", "id": "1", "lid": "a"}}`, 400, "/atomic:operations/1/ref"},
+					{`{"op": "remove"}`, 400, "/atomic:operations/1"},
+					{`{"op": "update", "data": {"type": "articles"}}`, 400, "/atomic:operations/1/data/id"},
+					{`{"op": "add", "data": [{"type": "articles"}, {"type": "articles"}]}`, 400, "/atomic:operations/1/data"},
+					{`{"op": "add", "ref": {"type": "articles", "id": "1", "relationship": "tags"}}`, 400, "/atomic:operations/1/data"},
+					{`{"op": "add", "data": {"attributes": {"title": "JSON API"}}}`, 422, "/atomic:operations/1/data/attributes/type"},
+				}
+				for _, test := range invalid {
+					_, err := parse(`{"atomic:operations": [{"op": "remove", "ref": {"type": "articles", "id": "2"}}, ` + test.op + `]}`)
+					So(err, ShouldNotBeNil)
+					So(err.Status, ShouldEqual, test.status)
+					So(err.Source.Pointer, ShouldEqual, test.pointer)
+				}
+			})
+		})
+
+		Convey("->OperationError()", func() {
+			err := OperationError(2, InputError("Invalid title", "title"))
+			So(err.Source.Pointer, ShouldEqual, "/atomic:operations/2/data/attributes/title")
+
+			err = OperationError(3, err)
+			So(err.Source.Pointer, ShouldEqual, "/atomic:operations/2/data/attributes/title")
+		})
+
+		Convey("->Send()", func() {
+			req, err := http.NewRequest("POST", "/operations", nil)
+			So(err, ShouldBeNil)
+			req.Header.Set("Accept", atomicType)
+			writer := httptest.NewRecorder()
+
+			object, objErr := NewObject("1", "articles", map[string]string{"title": "JSON API"})
+			So(objErr, ShouldBeNil)
+
+			sendErr := Send(writer, req, AtomicResults{{Data: object}, {}})
+			So(sendErr, ShouldBeNil)
+			So(writer.Code, ShouldEqual, http.StatusOK)
+			So(writer.Header().Get("Content-Type"), ShouldEqual, atomicType)
+
+			var body map[string]json.RawMessage
+			So(json.Unmarshal(writer.Body.Bytes(), &body), ShouldBeNil)
+			So(body, ShouldContainKey, "atomic:results")
+			So(body, ShouldNotContainKey, "data")
+
+			var results []map[string]interface{}
+			So(json.Unmarshal(body["atomic:results"], &results), ShouldBeNil)
+			So(results, ShouldHaveLength, 2)
+			So(results[1], ShouldBeEmpty)
+		})
+	})
+}
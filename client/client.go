@@ -1,7 +1,6 @@
 package jsc
 
 import (
-	"encoding/json"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -27,6 +26,22 @@ func Document(response *http.Response, mode jsh.DocumentMode) (*jsh.Document, *j
 	return document, nil
 }
 
+/*
+StreamDocument validates the HTTP response and returns a decoder yielding the resource
+objects of the response body one at a time, instead of decoding the whole document in
+memory (see jsh.DocumentDecoder). The body is closed at the end of the document, on
+error, or when the decoder is closed.
+*/
+func StreamDocument(response *http.Response) (*jsh.DocumentDecoder, *jsh.Error) {
+	decoder, err := buildParser(response).Stream(response.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	decoder.Document().Status = response.StatusCode
+	return decoder, nil
+}
+
 /*
 DumpBody is a convenience function that parses the body of the response into a
 string BUT DOESN'T close the ReadCloser. Useful for debugging.
@@ -73,6 +88,14 @@ func setIDPath(url *url.URL, resource, id string) {
 	}
 }
 
+/*
+BodyEncoder formats the JSON documents sent as request body. When nil, request
+bodies are formatted with jsh.DefaultEncoder:
+
+	jsc.BodyEncoder = &jsh.IndentedEncoder
+*/
+var BodyEncoder *jsh.Encoder
+
 /*
 prepareBody first prepares/validates the object to ensure it is JSON
 spec compatible, and then marshals it to JSON, sets the request body and
@@ -86,7 +109,11 @@ func prepareBody(request *http.Request, payload jsh.Sendable) error {
 
 	doc := jsh.Build(payload)
 
-	jsonContent, jsonErr := json.MarshalIndent(doc, "", " ")
+	encoder := jsh.DefaultEncoder
+	if BodyEncoder != nil {
+		encoder = *BodyEncoder
+	}
+	jsonContent, jsonErr := encoder.Marshal(doc)
 	if jsonErr != nil {
 		return fmt.Errorf("Unable to prepare JSON content: %v", jsonErr)
 	}
@@ -124,11 +151,67 @@ func Do(request *http.Request, mode jsh.DocumentMode) (*jsh.Document, *http.Resp
 	return doc, response, parseErr
 }
 
+/*
+DoStream sends the specified request as Do does, but returns a decoder of the response
+document instead of decoding it in memory, see StreamDocument. Useful for very large
+list responses:
+
+	decoder, response, err := jsc.DoStream(request)
+	if err != nil || decoder == nil {
+		return err
+	}
+	defer decoder.Close()
+
+	for {
+		object, err := decoder.Next()
+		...
+	}
+
+The decoder is nil for responses without JSON Document, see ParseResponse.
+*/
+func DoStream(request *http.Request) (*jsh.DocumentDecoder, *http.Response, error) {
+	client := &http.Client{}
+	response, clientErr := client.Do(request)
+
+	if clientErr != nil {
+		return nil, nil, fmt.Errorf(
+			"Error sending %s request: %s", request.Method, clientErr.Error(),
+		)
+	}
+
+	if !hasDocument(response) {
+		response.Body.Close()
+		return nil, response, nil
+	}
+
+	decoder, parseErr := StreamDocument(response)
+	if parseErr != nil {
+		return nil, response, fmt.Errorf("Error parsing response: %s", parseErr.Error())
+	}
+
+	return decoder, response, nil
+}
+
 /*
 ParseResponse handles parsing an HTTP response into a JSON Document if
 possible.
 */
 func ParseResponse(response *http.Response, mode jsh.DocumentMode) (*jsh.Document, error) {
+	if !hasDocument(response) {
+		return nil, nil
+	}
+
+	document, err := Document(response, mode)
+	if err != nil {
+		return nil, err
+	}
+
+	return document, nil
+}
+
+// hasDocument returns false for the response status codes that are not expected to
+// have a JSON Document.
+func hasDocument(response *http.Response) bool {
 	skipCodes := []int{
 		http.StatusNoContent,
 		http.StatusNotFound,
@@ -137,16 +220,10 @@ func ParseResponse(response *http.Response, mode jsh.DocumentMode) (*jsh.Documen
 
 	for _, code := range skipCodes {
 		if code == response.StatusCode {
-			return nil, nil
+			return false
 		}
 	}
-
-	document, err := Document(response, mode)
-	if err != nil {
-		return nil, err
-	}
-
-	return document, nil
+	return true
 }
 
 // NewRequest builds a basic request object with the necessary configurations to
@@ -98,6 +98,23 @@ func TestResponseParsing(t *testing.T) {
 				So(doc.HasData(), ShouldBeTrue)
 				So(doc.First().ID, ShouldEqual, "123")
 			})
+
+			Convey("should stream successfully", func() {
+				decoder, err := StreamDocument(response)
+				So(err, ShouldBeNil)
+				So(decoder.Document().Status, ShouldEqual, http.StatusOK)
+
+				for i := 0; i < 2; i++ {
+					object, err := decoder.Next()
+					So(err, ShouldBeNil)
+					So(object.ID, ShouldEqual, "123")
+				}
+
+				object, err := decoder.Next()
+				So(err, ShouldBeNil)
+				So(object, ShouldBeNil)
+				So(decoder.Document().JSONAPI, ShouldNotBeNil)
+			})
 		})
 	})
 }
@@ -100,6 +100,18 @@ func List(baseURL, resourceType string) (*jsh.Document, *http.Response, error) {
 	return Do(request, jsh.ListMode)
 }
 
+/*
+ListStream performs an outbound GET /resourceTypes request and returns a decoder
+yielding the resources one at a time, see DoStream.
+*/
+func ListStream(baseURL, resourceType string) (*jsh.DocumentDecoder, *http.Response, error) {
+	request, err := ListRequest(baseURL, resourceType)
+	if err != nil {
+		return nil, nil, err
+	}
+	return DoStream(request)
+}
+
 /*
 ListRequest returns a fully formatted JSONAPI List request. Useful if you need to
 set custom headers before proceeding. Otherwise just use "jsh.List".
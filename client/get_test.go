@@ -30,6 +30,20 @@ func TestGet(t *testing.T) {
 			})
 		})
 
+		Convey("->ListStream()", func() {
+
+			Convey("should stream an object listing request", func() {
+				decoder, resp, err := ListStream(baseURL, "tests")
+				So(err, ShouldBeNil)
+				So(resp.StatusCode, ShouldEqual, http.StatusOK)
+				defer decoder.Close()
+
+				object, objErr := decoder.Next()
+				So(objErr, ShouldBeNil)
+				So(object.Type, ShouldEqual, "tests")
+			})
+		})
+
 		Convey("->Fetch()", func() {
 
 			Convey("should handle a specific object request", func() {
@@ -0,0 +1,111 @@
+/*
+Command jsherrors renders the error catalog of jsh to Markdown or JSON, so that the API
+documentation lists every error clients can receive. The catalog contains the built-in
+codes of the convenience constructors, and the error definitions of the application
+read from a JSON file, an array of definitions as written by ErrorRegistry.WriteJSON:
+
+	//go:generate go run github.com/EtixLabs/go-json-spec-handler/cmd/jsherrors -definitions errors.json -output ERRORS.md
+
+Applications that register their errors from Go code at initialization render the
+catalog with jsh.ErrorCodes.WriteMarkdown from a program of their own instead.
+*/
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+
+	"github.com/EtixLabs/go-json-spec-handler"
+)
+
+const (
+	formatMarkdown = "markdown"
+	formatJSON     = "json"
+)
+
+func main() {
+	format := flag.String("format", formatMarkdown, "output format, markdown or json")
+	definitions := flag.String("definitions", "", "JSON file of the error definitions of the application")
+	output := flag.String("output", "", "output file name, defaults to the standard output")
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: jsherrors [-format markdown|json] [-definitions file.json] [-output file]")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	log.SetFlags(0)
+	log.SetPrefix("jsherrors: ")
+
+	if flag.NArg() > 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	var input io.Reader
+	if *definitions != "" {
+		file, err := os.Open(*definitions)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer file.Close()
+		input = file
+	}
+
+	var w io.Writer = os.Stdout
+	if *output != "" {
+		file, err := os.Create(*output)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer file.Close()
+		w = file
+	}
+
+	if err := render(w, *format, input); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// render registers the error definitions read from input, if any, in the default
+// registry of jsh and writes the catalog to w in the given format.
+func render(w io.Writer, format string, input io.Reader) error {
+	if format != formatMarkdown && format != formatJSON {
+		return fmt.Errorf("unknown format '%s'", format)
+	}
+	if input != nil {
+		if err := register(input); err != nil {
+			return err
+		}
+	}
+	if format == formatJSON {
+		return jsh.ErrorCodes.WriteJSON(w)
+	}
+	return jsh.ErrorCodes.WriteMarkdown(w)
+}
+
+// register adds the JSON array of error definitions to the default registry of jsh.
+func register(input io.Reader) (err error) {
+	content, err := ioutil.ReadAll(input)
+	if err != nil {
+		return err
+	}
+	var definitions []jsh.ErrorDefinition
+	if err := json.Unmarshal(content, &definitions); err != nil {
+		return fmt.Errorf("invalid error definitions: %s", err)
+	}
+
+	// Register panics on invalid definitions, as it is meant to be called at initialization
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("invalid error definitions: %v", r)
+		}
+	}()
+	for _, definition := range definitions {
+		jsh.RegisterError(definition)
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/EtixLabs/go-json-spec-handler"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestRender(t *testing.T) {
+
+	Convey("Render Tests", t, func() {
+
+		var buffer bytes.Buffer
+
+		Convey("->render()", func() {
+
+			Convey("should render the built-in codes as Markdown", func() {
+				So(render(&buffer, formatMarkdown, nil), ShouldBeNil)
+				So(buffer.String(), ShouldStartWith, "| Code | Status | Title | Description |\n")
+				So(buffer.String(), ShouldContainSubstring, "| `not_found` | 404 Not Found | Not Found |")
+			})
+
+			Convey("should render the definitions of the application as JSON", func() {
+				input := `[{"code": "quota_exceeded", "status": 429, "title": "Quota of %d requests exceeded"}]`
+				So(render(&buffer, formatJSON, strings.NewReader(input)), ShouldBeNil)
+
+				var definitions []jsh.ErrorDefinition
+				So(json.Unmarshal(buffer.Bytes(), &definitions), ShouldBeNil)
+				So(len(definitions), ShouldBeGreaterThan, 1)
+
+				definition, ok := jsh.ErrorCodes.Lookup("quota_exceeded")
+				So(ok, ShouldBeTrue)
+				So(definition.Status, ShouldEqual, 429)
+				So(definitions, ShouldContain, *definition)
+			})
+
+			Convey("should reject invalid definitions", func() {
+				for _, input := range []string{`{}`, `[{"status": 400}]`, `[{"code": "not_found", "status": 404}]`} {
+					So(render(&buffer, formatMarkdown, strings.NewReader(input)), ShouldNotBeNil)
+				}
+			})
+
+			Convey("should reject unknown formats", func() {
+				So(render(&buffer, "html", nil), ShouldNotBeNil)
+			})
+		})
+	})
+}
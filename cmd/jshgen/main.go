@@ -0,0 +1,614 @@
+/*
+Command jshgen generates reflection-free jsh methods for the models of a Go file, to
+remove the cost of reflection from ProcessCreate, ProcessUpdate and Object.Marshal on
+large endpoints. Add a go generate directive to the file declaring the models:
+
+	//go:generate go run github.com/EtixLabs/go-json-spec-handler/cmd/jshgen -type User,Group
+
+For each model, a struct with json and jsh tags, jshgen writes to <file>_jsh.go:
+
+  - ValidateObject, implementing jsh.ObjectValidator: the create and update permission
+    checks of the jsh tags and the assignment of relationships, as jsh.Validator does
+  - MarshalAttributes, implementing jsh.AttributesMarshaler: the marshaling of the
+    attributes field by field, as encoding/json does
+
+ProcessCreate, ProcessUpdate and Object.Marshal fall back to reflection for the models
+that were not generated. The govalidator rules of the valid tags are still checked by
+Object.Unmarshal. A method is not generated when the model uses features that cannot be
+resolved from the file alone (e.g. omitempty on a named type, or embedded structs for
+MarshalAttributes), the model then keeps the reflection-based behavior for this method.
+*/
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"log"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// jshPath is the import path of the jsh package.
+const jshPath = "github.com/EtixLabs/go-json-spec-handler"
+
+func main() {
+	typeNames := flag.String("type", "", "comma-separated list of model names, defaults to every struct with jsh tags")
+	output := flag.String("output", "", "output file name, defaults to <file>_jsh.go")
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: jshgen [-type T1,T2] [-output file] [file.go]")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	log.SetFlags(0)
+	log.SetPrefix("jshgen: ")
+
+	filename := os.Getenv("GOFILE")
+	if flag.NArg() > 0 {
+		filename = flag.Arg(0)
+	}
+	if filename == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	var types []string
+	if *typeNames != "" {
+		types = strings.Split(*typeNames, ",")
+	}
+	src, err := generate(filename, nil, types)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if *output == "" {
+		*output = outputName(filename)
+	}
+	if err := ioutil.WriteFile(*output, src, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// outputName returns the name of the generated file for the given source file. The
+// generated file of a test file is a test file as well.
+func outputName(filename string) string {
+	if strings.HasSuffix(filename, "_test.go") {
+		return strings.TrimSuffix(filename, "_test.go") + "_jsh_test.go"
+	}
+	return strings.TrimSuffix(filename, ".go") + "_jsh.go"
+}
+
+// generate parses the Go source file and returns the generated code for the models
+// with the given names, or for every struct with jsh tags if names is empty.
+func generate(filename string, src interface{}, names []string) ([]byte, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, filename, src, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	g := &generator{
+		jshName: importName(file, jshPath),
+		methods: declaredMethods(file),
+	}
+	selected := map[string]bool{}
+	for _, name := range names {
+		selected[strings.TrimSpace(name)] = true
+	}
+
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			typeSpec := spec.(*ast.TypeSpec)
+			structType, ok := typeSpec.Type.(*ast.StructType)
+			name := typeSpec.Name.Name
+			if len(selected) > 0 {
+				if !selected[name] {
+					continue
+				}
+				delete(selected, name)
+				if !ok {
+					return nil, fmt.Errorf("%s is not a struct", name)
+				}
+			} else if !ok || !hasJSHTags(structType) {
+				continue
+			}
+			if typeSpec.TypeParams != nil {
+				return nil, fmt.Errorf("%s: generic models are not supported", name)
+			}
+			g.model(name, structType)
+		}
+	}
+	for name := range selected {
+		return nil, fmt.Errorf("type %s not found in %s", name, filename)
+	}
+	return g.source(file.Name.Name)
+}
+
+// generator writes the methods of the models.
+type generator struct {
+	// jshName is the name of the jsh package in the source file
+	jshName string
+	// methods are the names of the methods declared in the source file, by receiver type
+	methods map[string]map[string]bool
+	body    bytes.Buffer
+	imports map[string]bool
+}
+
+// field is a field of a model.
+type field struct {
+	name     string
+	ref      string
+	typ      ast.Expr
+	json     string
+	jsh      string
+	embedded bool
+}
+
+// model writes the methods of the model.
+func (g *generator) model(name string, structType *ast.StructType) {
+	fields := modelFields(structType)
+	for _, method := range []struct {
+		name  string
+		write func(*bytes.Buffer, string, []field) error
+	}{
+		{"ValidateObject", g.validateObject},
+		{"MarshalAttributes", g.marshalAttributes},
+	} {
+		if g.methods[name][method.name] {
+			continue
+		}
+		var code bytes.Buffer
+		if err := method.write(&code, name, fields); err != nil {
+			log.Printf("%s.%s is not generated, %s", name, method.name, err)
+			continue
+		}
+		g.body.Write(code.Bytes())
+	}
+}
+
+// validateObject writes the ValidateObject method of the model, see jsh.Validator.
+func (g *generator) validateObject(w *bytes.Buffer, name string, fields []field) error {
+	if g.jshName == "" && hasRelationships(fields) {
+		return fmt.Errorf("relationship fields require the import of %s", jshPath)
+	}
+
+	var body bytes.Buffer
+	needsStrconv := false
+	for _, f := range fields {
+		if !ast.IsExported(f.name) {
+			continue
+		}
+		tags := jshTags(f.jsh)
+		if tags[tagToOne] || tags[tagToMany] {
+			code, usesStrconv, err := g.relationship(f, tags[tagToMany])
+			if err != nil {
+				return err
+			}
+			needsStrconv = needsStrconv || usesStrconv
+			body.WriteString(code)
+			continue
+		}
+
+		attribute := strings.Split(f.json, ",")[0]
+		if attribute == "-" {
+			continue
+		}
+		kind := classify(f.typ)
+		if kind.leaf() {
+			fmt.Fprintf(&body, "if _, ok := v.Attribute(%q, %q, %s); ok {\n", attribute, f.jsh, kind.zero(f.ref))
+			fmt.Fprintf(&body, "v.Set(%q)\n}\n", attribute)
+		} else {
+			fmt.Fprintf(&body, "if jValue, ok := v.Attribute(%q, %q, %s); ok {\n", attribute, f.jsh, kind.zero(f.ref))
+			fmt.Fprintf(&body, "v.Nested(%q, &%s, jValue)\n}\n", attribute, f.ref)
+		}
+	}
+
+	g.use(jshPath)
+	if needsStrconv {
+		g.use("strconv")
+	}
+	fmt.Fprintf(w, "\n// ValidateObject implements jsh.ObjectValidator, see jsh.Validator.\n")
+	fmt.Fprintf(w, "func (m *%s) ValidateObject(object *jsh.Object, action string) ([]string, jsh.ErrorList) {\n", name)
+	fmt.Fprintf(w, "if m == nil {\n")
+	fmt.Fprintf(w, "return nil, jsh.ErrorList{jsh.ISE(\"The argument to \" + action + \" must be a non-nil pointer\")}\n}\n")
+	fmt.Fprintf(w, "v, err := jsh.NewModelValidation(object, action)\n")
+	fmt.Fprintf(w, "if err != nil {\nreturn nil, jsh.ErrorList{err}\n}\n")
+	w.Write(body.Bytes())
+	fmt.Fprintf(w, "return v.Result()\n}\n")
+	return nil
+}
+
+// relationship returns the code validating and setting a relationship field, as
+// setModelRelationship does. Invalid field types fail when the relationship is set.
+func (g *generator) relationship(f field, many bool) (string, bool, error) {
+	var body bytes.Buffer
+	relationship := lowerFirstRune(f.name)
+	fmt.Fprintf(&body, "if rel := v.Relationship(%q, %q, %t); rel != nil {\n", f.name, f.jsh, many)
+	fail := func(msg string) (string, bool, error) {
+		fmt.Fprintf(&body, "v.Fail(jsh.ISE(%q))\n}\n", msg)
+		return body.String(), false, nil
+	}
+
+	if !many {
+		if !g.acceptsIDObject(f.typ) {
+			if classify(f.typ) == kindUnknown {
+				return "", false, fmt.Errorf("cannot resolve the type of relationship %s", f.name)
+			}
+			return fail("Invalid field type for to-one relation, must be *IDObject")
+		}
+		fmt.Fprintf(&body, "%s = rel.Data[0]\nv.Set(%q)\n}\n", f.ref, relationship)
+		return body.String(), false, nil
+	}
+
+	mapType, ok := f.typ.(*ast.MapType)
+	if !ok {
+		if classify(f.typ) == kindUnknown {
+			return "", false, fmt.Errorf("cannot resolve the type of relationship %s", f.name)
+		}
+		return fail("Invalid field type for to-many relation, must be map")
+	}
+	key, _ := mapType.Key.(*ast.Ident)
+	switch {
+	case key != nil && (key.Name == "string" || key.Name == "int"):
+	case classify(mapType.Key) == kindUnknown:
+		return "", false, fmt.Errorf("cannot resolve the key type of relationship %s", f.name)
+	default:
+		return fail("Invalid map key type for to-many relation, must be string or int")
+	}
+	if !g.acceptsIDObject(mapType.Value) {
+		if classify(mapType.Value) == kindUnknown {
+			return "", false, fmt.Errorf("cannot resolve the value type of relationship %s", f.name)
+		}
+		return fail("Invalid map value type for to-many relation, must be *IDObject")
+	}
+
+	if key.Name == "string" {
+		fmt.Fprintf(&body, "for _, data := range rel.Data {\n%s[data.ID] = data\n}\n", f.ref)
+		fmt.Fprintf(&body, "v.Set(%q)\n}\n", relationship)
+		return body.String(), false, nil
+	}
+	fmt.Fprintf(&body, "valid := true\n")
+	fmt.Fprintf(&body, "for _, data := range rel.Data {\n")
+	fmt.Fprintf(&body, "id, err := strconv.Atoi(data.ID)\n")
+	fmt.Fprintf(&body, "if err != nil {\n")
+	fmt.Fprintf(&body, "v.Fail(jsh.RelationshipError(\"Invalid resource ID\", %q))\n", relationship)
+	fmt.Fprintf(&body, "valid = false\nbreak\n}\n")
+	fmt.Fprintf(&body, "%s[id] = data\n}\n", f.ref)
+	fmt.Fprintf(&body, "if valid {\nv.Set(%q)\n}\n}\n", relationship)
+	return body.String(), true, nil
+}
+
+// marshalAttributes writes the MarshalAttributes method of the model, see encoding/json.
+func (g *generator) marshalAttributes(w *bytes.Buffer, name string, fields []field) error {
+	if g.methods[name]["MarshalJSON"] || g.methods[name]["MarshalText"] {
+		return fmt.Errorf("%s implements its own JSON marshaling", name)
+	}
+
+	var body bytes.Buffer
+	seen := map[string]bool{}
+	for _, f := range fields {
+		if f.embedded {
+			return fmt.Errorf("embedded field %s is not supported", f.name)
+		}
+		if !ast.IsExported(f.name) || f.json == "-" {
+			continue
+		}
+		options := strings.Split(f.json, ",")
+		attribute := options[0]
+		if attribute == "" {
+			attribute = f.name
+		}
+		if seen[attribute] {
+			return fmt.Errorf("attribute %s is declared twice", attribute)
+		}
+		seen[attribute] = true
+
+		omitEmpty := false
+		for _, option := range options[1:] {
+			switch option {
+			case "omitempty":
+				omitEmpty = true
+			case "":
+			default:
+				return fmt.Errorf("json option %s of field %s is not supported", option, f.name)
+			}
+		}
+		key, err := json.Marshal(attribute)
+		if err != nil {
+			return err
+		}
+
+		write := fmt.Sprintf("if err := write(%s, &%s); err != nil {\nreturn nil, err\n}\n", strconv.Quote(string(key)+":"), f.ref)
+		if omitEmpty {
+			nonEmpty, ok := classify(f.typ).nonEmpty(f.ref)
+			if !ok {
+				return fmt.Errorf("cannot resolve omitempty for the type of field %s", f.name)
+			}
+			if nonEmpty != "" {
+				write = fmt.Sprintf("if %s {\n%s}\n", nonEmpty, write)
+			}
+		}
+		body.WriteString(write)
+	}
+
+	g.use("bytes")
+	fmt.Fprintf(w, "\n// MarshalAttributes implements jsh.AttributesMarshaler, see encoding/json.\n")
+	fmt.Fprintf(w, "func (m *%s) MarshalAttributes() ([]byte, error) {\n", name)
+	fmt.Fprintf(w, "if m == nil {\nreturn []byte(\"null\"), nil\n}\n")
+	fmt.Fprintf(w, "var buf bytes.Buffer\nbuf.WriteByte('{')\n")
+	if body.Len() > 0 {
+		g.use("encoding/json")
+		fmt.Fprintf(w, "write := func(key string, field interface{}) error {\n")
+		fmt.Fprintf(w, "value, err := json.Marshal(field)\n")
+		fmt.Fprintf(w, "if err != nil {\nreturn err\n}\n")
+		fmt.Fprintf(w, "if buf.Len() > 1 {\nbuf.WriteByte(',')\n}\n")
+		fmt.Fprintf(w, "buf.WriteString(key)\nbuf.Write(value)\nreturn nil\n}\n")
+	}
+	w.Write(body.Bytes())
+	fmt.Fprintf(w, "buf.WriteByte('}')\nreturn buf.Bytes(), nil\n}\n")
+	return nil
+}
+
+// acceptsIDObject returns true if a *jsh.IDObject can be assigned to the type.
+func (g *generator) acceptsIDObject(typ ast.Expr) bool {
+	switch t := typ.(type) {
+	case *ast.StarExpr:
+		selector, ok := t.X.(*ast.SelectorExpr)
+		if !ok {
+			return false
+		}
+		pkg, ok := selector.X.(*ast.Ident)
+		return ok && pkg.Name == g.jshName && selector.Sel.Name == "IDObject"
+	case *ast.InterfaceType:
+		return t.Methods == nil || len(t.Methods.List) == 0
+	case *ast.Ident:
+		return t.Name == "any"
+	}
+	return false
+}
+
+// use adds the import path to the generated file.
+func (g *generator) use(path string) {
+	if g.imports == nil {
+		g.imports = map[string]bool{}
+	}
+	g.imports[path] = true
+}
+
+// source returns the formatted generated file.
+func (g *generator) source(pkg string) ([]byte, error) {
+	var src bytes.Buffer
+	fmt.Fprintf(&src, "// Code generated by jshgen; DO NOT EDIT.\n\npackage %s\n\n", pkg)
+	if len(g.imports) > 0 {
+		src.WriteString("import (\n")
+		for _, path := range []string{"bytes", "encoding/json", "strconv", jshPath} {
+			if !g.imports[path] {
+				continue
+			}
+			if path == jshPath {
+				fmt.Fprintf(&src, "\njsh %q\n", path)
+			} else {
+				fmt.Fprintf(&src, "%q\n", path)
+			}
+		}
+		src.WriteString(")\n")
+	}
+	src.Write(g.body.Bytes())
+	return format.Source(src.Bytes())
+}
+
+// kind classifies the types of fields, as far as it can be resolved from the source file.
+type kind int
+
+const (
+	kindUnknown kind = iota
+	kindString
+	kindBool
+	kindNumber
+	// kindNil types are compared to nil: pointers, interfaces, functions and channels
+	kindNil
+	// kindLen types are compared to nil, and are empty if their length is 0: maps and slices
+	kindLen
+	kindArray
+	kindStruct
+)
+
+// classify returns the kind of the type.
+func classify(typ ast.Expr) kind {
+	switch t := typ.(type) {
+	case *ast.Ident:
+		switch t.Name {
+		case "string":
+			return kindString
+		case "bool":
+			return kindBool
+		case "int", "int8", "int16", "int32", "int64",
+			"uint", "uint8", "uint16", "uint32", "uint64", "uintptr",
+			"float32", "float64", "complex64", "complex128", "byte", "rune":
+			return kindNumber
+		case "error", "any":
+			return kindNil
+		}
+	case *ast.StarExpr, *ast.InterfaceType, *ast.FuncType, *ast.ChanType:
+		return kindNil
+	case *ast.MapType:
+		return kindLen
+	case *ast.ArrayType:
+		if t.Len == nil {
+			return kindLen
+		}
+		return kindArray
+	case *ast.StructType:
+		return kindStruct
+	case *ast.ParenExpr:
+		return classify(t.X)
+	}
+	return kindUnknown
+}
+
+// leaf returns true if the validator does not validate nested values of the kind.
+func (k kind) leaf() bool {
+	return k == kindString || k == kindBool || k == kindNumber
+}
+
+// zero returns the expression checking if the field is the zero value of its type.
+func (k kind) zero(ref string) string {
+	switch k {
+	case kindString:
+		return ref + ` == ""`
+	case kindBool:
+		return "!" + ref
+	case kindNumber:
+		return ref + " == 0"
+	case kindNil, kindLen:
+		return ref + " == nil"
+	}
+	return "v.Zero(&" + ref + ")"
+}
+
+// nonEmpty returns the expression checking if the field is not empty for the json
+// omitempty option, or an empty string if the field is never empty. It returns false
+// if the emptiness cannot be resolved.
+func (k kind) nonEmpty(ref string) (string, bool) {
+	switch k {
+	case kindString:
+		return ref + ` != ""`, true
+	case kindBool:
+		return ref, true
+	case kindNumber:
+		return ref + " != 0", true
+	case kindNil:
+		return ref + " != nil", true
+	case kindLen:
+		return "len(" + ref + ") != 0", true
+	case kindStruct:
+		return "", true
+	}
+	return "", false
+}
+
+// modelFields returns the fields of the struct in declaration order.
+func modelFields(structType *ast.StructType) []field {
+	var fields []field
+	for _, f := range structType.Fields.List {
+		tag := reflect.StructTag("")
+		if f.Tag != nil {
+			if value, err := strconv.Unquote(f.Tag.Value); err == nil {
+				tag = reflect.StructTag(value)
+			}
+		}
+		if len(f.Names) == 0 {
+			name := embeddedName(f.Type)
+			fields = append(fields, field{name, "m." + name, f.Type, tag.Get("json"), tag.Get("jsh"), true})
+			continue
+		}
+		for _, name := range f.Names {
+			fields = append(fields, field{name.Name, "m." + name.Name, f.Type, tag.Get("json"), tag.Get("jsh"), false})
+		}
+	}
+	return fields
+}
+
+// embeddedName returns the field name of an embedded type.
+func embeddedName(typ ast.Expr) string {
+	switch t := typ.(type) {
+	case *ast.StarExpr:
+		return embeddedName(t.X)
+	case *ast.SelectorExpr:
+		return t.Sel.Name
+	case *ast.Ident:
+		return t.Name
+	}
+	return ""
+}
+
+// hasJSHTags returns true if a field of the struct has a jsh tag.
+func hasJSHTags(structType *ast.StructType) bool {
+	for _, f := range modelFields(structType) {
+		if f.jsh != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// hasRelationships returns true if a field is tagged as a relationship.
+func hasRelationships(fields []field) bool {
+	for _, f := range fields {
+		tags := jshTags(f.jsh)
+		if tags[tagToOne] || tags[tagToMany] {
+			return true
+		}
+	}
+	return false
+}
+
+// Tag names of jsh relationships
+const (
+	tagToOne  = "one"
+	tagToMany = "many"
+)
+
+// jshTags returns the names of the jsh tags, without their options.
+func jshTags(rawTags string) map[string]bool {
+	tags := map[string]bool{}
+	for _, option := range strings.Split(rawTags, ",") {
+		tags[strings.SplitN(option, "/", 2)[0]] = true
+	}
+	return tags
+}
+
+// importName returns the name of the package imported with the path in the file, or
+// an empty string if the file does not import it.
+func importName(file *ast.File, path string) string {
+	for _, spec := range file.Imports {
+		if importPath, _ := strconv.Unquote(spec.Path.Value); importPath != path {
+			continue
+		}
+		if spec.Name != nil {
+			return spec.Name.Name
+		}
+		return "jsh"
+	}
+	return ""
+}
+
+// declaredMethods returns the names of the methods declared in the file by receiver type.
+func declaredMethods(file *ast.File) map[string]map[string]bool {
+	methods := map[string]map[string]bool{}
+	for _, decl := range file.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Recv == nil || len(fn.Recv.List) == 0 {
+			continue
+		}
+		receiver := embeddedName(fn.Recv.List[0].Type)
+		if methods[receiver] == nil {
+			methods[receiver] = map[string]bool{}
+		}
+		methods[receiver][fn.Name.Name] = true
+	}
+	return methods
+}
+
+// lowerFirstRune changes the first rune of the given string to lower case.
+func lowerFirstRune(s string) string {
+	if len(s) == 0 {
+		return s
+	}
+	a := []rune(s)
+	a[0] = unicode.ToLower(a[0])
+	return string(a)
+}
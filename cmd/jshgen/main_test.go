@@ -0,0 +1,96 @@
+package main
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+const testSource = `package models
+
+import api "github.com/EtixLabs/go-json-spec-handler"
+
+type User struct {
+	Name   string        ` + "`json:\"name\" jsh:\"create\"`" + `
+	Status Status        ` + "`json:\"status,omitempty\" jsh:\"update\"`" + `
+	Group  *api.IDObject ` + "`json:\"-\" jsh:\"one,create\"`" + `
+	Owner  *User         ` + "`json:\"-\" jsh:\"one\"`" + `
+	Tags   []string      ` + "`json:\"tags,string\" jsh:\"many\"`" + `
+	hidden string
+}
+
+type Status string
+
+type Custom struct {
+	Base
+	Name string ` + "`json:\"name\" jsh:\"create\"`" + `
+}
+
+type Base struct {
+	ID string
+}
+
+func (c *Custom) MarshalJSON() ([]byte, error) {
+	return nil, nil
+}
+
+type Empty struct {
+	ID string ` + "`json:\"-\" jsh:\"primary,empties\"`" + `
+}
+`
+
+func TestGenerate(t *testing.T) {
+
+	Convey("Generate Tests", t, func() {
+
+		Convey("->generate()", func() {
+
+			Convey("should generate every model with jsh tags", func() {
+				src, err := generate("models.go", testSource, nil)
+				So(err, ShouldBeNil)
+				code := string(src)
+				So(code, ShouldStartWith, "// Code generated by jshgen; DO NOT EDIT.\n\npackage models\n")
+				So(code, ShouldContainSubstring, "func (m *User) ValidateObject(")
+				So(code, ShouldContainSubstring, "func (m *Custom) ValidateObject(")
+				So(code, ShouldContainSubstring, "func (m *Empty) ValidateObject(")
+				So(code, ShouldNotContainSubstring, "func (m *Base)")
+				So(code, ShouldNotContainSubstring, "hidden")
+			})
+
+			Convey("should assign relationships as the validator", func() {
+				src, err := generate("models.go", testSource, []string{"User"})
+				So(err, ShouldBeNil)
+				code := string(src)
+				So(code, ShouldContainSubstring, `if rel := v.Relationship("Group", "one,create", false); rel != nil {`)
+				So(code, ShouldContainSubstring, "m.Group = rel.Data[0]")
+				So(code, ShouldContainSubstring, `v.Fail(jsh.ISE("Invalid field type for to-one relation, must be *IDObject"))`)
+				So(code, ShouldContainSubstring, `v.Fail(jsh.ISE("Invalid field type for to-many relation, must be map"))`)
+				So(code, ShouldContainSubstring, `v.Attribute("status", "update", v.Zero(&m.Status))`)
+				So(code, ShouldContainSubstring, `v.Nested("status", &m.Status, jValue)`)
+			})
+
+			Convey("should fall back to reflection for unresolved attributes", func() {
+				src, err := generate("models.go", testSource, nil)
+				So(err, ShouldBeNil)
+				code := string(src)
+				So(code, ShouldNotContainSubstring, "func (m *User) MarshalAttributes(")
+				So(code, ShouldNotContainSubstring, "func (m *Custom) MarshalAttributes(")
+				So(code, ShouldContainSubstring, "func (m *Empty) MarshalAttributes(")
+				So(code, ShouldNotContainSubstring, `"encoding/json"`)
+			})
+
+			Convey("should reject unknown types", func() {
+				_, err := generate("models.go", testSource, []string{"Unknown"})
+				So(err, ShouldNotBeNil)
+
+				_, err = generate("models.go", testSource, []string{"Status"})
+				So(err, ShouldNotBeNil)
+			})
+		})
+
+		Convey("->outputName()", func() {
+			So(outputName("models.go"), ShouldEqual, "models_jsh.go")
+			So(outputName("models_test.go"), ShouldEqual, "models_jsh_test.go")
+		})
+	})
+}
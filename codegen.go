@@ -0,0 +1,125 @@
+package jsh
+
+import (
+	"encoding/json"
+	"reflect"
+)
+
+/*
+ObjectValidator is implemented by the models generated with jshgen (see cmd/jshgen).
+ProcessCreate and ProcessUpdate call ValidateObject instead of the reflection-based
+Validator, it must perform exactly the same checks.
+*/
+type ObjectValidator interface {
+	ValidateObject(object *Object, action string) ([]string, ErrorList)
+}
+
+/*
+AttributesMarshaler is implemented by the models generated with jshgen (see cmd/jshgen).
+Object.Marshal calls MarshalAttributes instead of encoding/json reflection, it must
+return the same JSON as json.Marshal.
+*/
+type AttributesMarshaler interface {
+	MarshalAttributes() ([]byte, error)
+}
+
+/*
+ModelValidation performs the checks of Validator.Validate field by field, for the
+ValidateObject methods generated by jshgen. It is not meant to be used directly:
+
+	v, err := jsh.NewModelValidation(object, action)
+	if err != nil {
+		return nil, jsh.ErrorList{err}
+	}
+	if rel := v.Relationship("Group", "one,create,update", false); rel != nil {
+		m.Group = rel.Data[0]
+		v.Set("group")
+	}
+	if _, ok := v.Attribute("username", "create/required", m.Name == ""); ok {
+		v.Set("username")
+	}
+	return v.Result()
+*/
+type ModelValidation struct {
+	validator *Validator
+	attrs     map[string]json.RawMessage
+	fields    []string
+	errors    ErrorList
+}
+
+// NewModelValidation starts the validation of a model for the object and the action
+// (i.e. create, update).
+func NewModelValidation(object *Object, action string) (*ModelValidation, *Error) {
+	v := NewValidator(object, action)
+	attrs, err := v.decodeKeys(object.Attributes)
+	if err != nil {
+		return nil, err
+	}
+	return &ModelValidation{validator: v, attrs: attrs}, nil
+}
+
+// Relationship removes the relationship of the given field from the object and validates
+// it against the jsh tags of the field. It returns the relationship if it must be set
+// to the field.
+func (v *ModelValidation) Relationship(field, rawTags string, many bool) *Relationship {
+	rel := v.validator.takeRelationship(field)
+	hasValue, err := validateModelRelationship(toLowerFirstRune(field), many, rel, decodeFieldTag(rawTags, v.validator.action))
+	if err != nil {
+		v.Fail(err)
+		return nil
+	}
+	if !hasValue {
+		return nil
+	}
+	return rel
+}
+
+// Attribute removes the attribute from the provided attributes and validates the field
+// value against the jsh tags of the field. It returns the provided JSON value and true
+// if the field value must be reported, see Set and Nested.
+func (v *ModelValidation) Attribute(attribute, rawTags string, zero bool) (json.RawMessage, bool) {
+	jValue := takeAttribute(v.attrs, attribute)
+	hasValue, err := validateModelField(attribute, zero, decodeFieldTag(rawTags, v.validator.action))
+	if err != nil {
+		v.Fail(err)
+		return nil, false
+	}
+	return jValue, hasValue
+}
+
+// Nested reports the field and validates the nested values of a composite field
+// (struct, map, slice...). The value is a pointer to the field.
+func (v *ModelValidation) Nested(path string, value interface{}, jValue json.RawMessage) {
+	fields, errors := v.validator.nestedResult(path, reflect.ValueOf(value), jValue)
+	if errors != nil {
+		v.errors = append(v.errors, errors...)
+		return
+	}
+	v.fields = append(v.fields, fields...)
+}
+
+// Zero returns true if the field is the zero value of its type, for the types whose zero
+// value cannot be compared directly. The value is a pointer to the field.
+func (v *ModelValidation) Zero(value interface{}) bool {
+	return isZero(reflect.ValueOf(value).Elem())
+}
+
+// Set reports a field that was unmarshaled to the model.
+func (v *ModelValidation) Set(field string) {
+	v.fields = append(v.fields, field)
+}
+
+// Fail adds a validation error.
+func (v *ModelValidation) Fail(err *Error) {
+	v.errors = append(v.errors, err)
+}
+
+// Result adds the errors for the attributes and relationships that do not exist in the
+// model, and returns the reported fields or the validation errors.
+func (v *ModelValidation) Result() ([]string, ErrorList) {
+	errors := append(v.errors, v.validator.unknownFields("", v.attrs)...)
+	if errors != nil {
+		return nil, errors
+	}
+	return v.fields, nil
+}
@@ -0,0 +1,156 @@
+// Code generated by jshgen; DO NOT EDIT.
+
+package jsh_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+
+	jsh "github.com/EtixLabs/go-json-spec-handler"
+)
+
+// ValidateObject implements jsh.ObjectValidator, see jsh.Validator.
+func (m *GeneratedUser) ValidateObject(object *jsh.Object, action string) ([]string, jsh.ErrorList) {
+	if m == nil {
+		return nil, jsh.ErrorList{jsh.ISE("The argument to " + action + " must be a non-nil pointer")}
+	}
+	v, err := jsh.NewModelValidation(object, action)
+	if err != nil {
+		return nil, jsh.ErrorList{err}
+	}
+	if _, ok := v.Attribute("name", "create/required,update", m.Name == ""); ok {
+		v.Set("name")
+	}
+	if _, ok := v.Attribute("email", "create,update", m.Email == ""); ok {
+		v.Set("email")
+	}
+	if _, ok := v.Attribute("age", "update", m.Age == 0); ok {
+		v.Set("age")
+	}
+	if _, ok := v.Attribute("admin", "", !m.Admin); ok {
+		v.Set("admin")
+	}
+	if rel := v.Relationship("Group", "one,create/required,update", false); rel != nil {
+		m.Group = rel.Data[0]
+		v.Set("group")
+	}
+	if rel := v.Relationship("Friends", "many,create", true); rel != nil {
+		for _, data := range rel.Data {
+			m.Friends[data.ID] = data
+		}
+		v.Set("friends")
+	}
+	if rel := v.Relationship("Teams", "many,update", true); rel != nil {
+		valid := true
+		for _, data := range rel.Data {
+			id, err := strconv.Atoi(data.ID)
+			if err != nil {
+				v.Fail(jsh.RelationshipError("Invalid resource ID", "teams"))
+				valid = false
+				break
+			}
+			m.Teams[id] = data
+		}
+		if valid {
+			v.Set("teams")
+		}
+	}
+	if jValue, ok := v.Attribute("address", "create,update", m.Address == nil); ok {
+		v.Nested("address", &m.Address, jValue)
+	}
+	if jValue, ok := v.Attribute("labels", "create", m.Labels == nil); ok {
+		v.Nested("labels", &m.Labels, jValue)
+	}
+	return v.Result()
+}
+
+// MarshalAttributes implements jsh.AttributesMarshaler, see encoding/json.
+func (m *GeneratedUser) MarshalAttributes() ([]byte, error) {
+	if m == nil {
+		return []byte("null"), nil
+	}
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	write := func(key string, field interface{}) error {
+		value, err := json.Marshal(field)
+		if err != nil {
+			return err
+		}
+		if buf.Len() > 1 {
+			buf.WriteByte(',')
+		}
+		buf.WriteString(key)
+		buf.Write(value)
+		return nil
+	}
+	if err := write("\"name\":", &m.Name); err != nil {
+		return nil, err
+	}
+	if m.Email != "" {
+		if err := write("\"email\":", &m.Email); err != nil {
+			return nil, err
+		}
+	}
+	if m.Age != 0 {
+		if err := write("\"age\":", &m.Age); err != nil {
+			return nil, err
+		}
+	}
+	if err := write("\"admin\":", &m.Admin); err != nil {
+		return nil, err
+	}
+	if m.Address != nil {
+		if err := write("\"address\":", &m.Address); err != nil {
+			return nil, err
+		}
+	}
+	if len(m.Labels) != 0 {
+		if err := write("\"labels\":", &m.Labels); err != nil {
+			return nil, err
+		}
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// ValidateObject implements jsh.ObjectValidator, see jsh.Validator.
+func (m *GeneratedAddress) ValidateObject(object *jsh.Object, action string) ([]string, jsh.ErrorList) {
+	if m == nil {
+		return nil, jsh.ErrorList{jsh.ISE("The argument to " + action + " must be a non-nil pointer")}
+	}
+	v, err := jsh.NewModelValidation(object, action)
+	if err != nil {
+		return nil, jsh.ErrorList{err}
+	}
+	if _, ok := v.Attribute("city", "create,update/required", m.City == ""); ok {
+		v.Set("city")
+	}
+	return v.Result()
+}
+
+// MarshalAttributes implements jsh.AttributesMarshaler, see encoding/json.
+func (m *GeneratedAddress) MarshalAttributes() ([]byte, error) {
+	if m == nil {
+		return []byte("null"), nil
+	}
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	write := func(key string, field interface{}) error {
+		value, err := json.Marshal(field)
+		if err != nil {
+			return err
+		}
+		if buf.Len() > 1 {
+			buf.WriteByte(',')
+		}
+		buf.WriteString(key)
+		buf.Write(value)
+		return nil
+	}
+	if err := write("\"city\":", &m.City); err != nil {
+		return nil, err
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
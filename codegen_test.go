@@ -0,0 +1,141 @@
+package jsh_test
+
+//go:generate go run ./cmd/jshgen -type GeneratedUser,GeneratedAddress
+
+import (
+	"encoding/json"
+	"testing"
+
+	jsh "github.com/EtixLabs/go-json-spec-handler"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+type GeneratedUser struct {
+	ID      string                   `json:"-"`
+	Name    string                   `json:"name"              jsh:"create/required,update"`
+	Email   string                   `json:"email,omitempty"   jsh:"create,update" valid:"email"`
+	Age     int                      `json:"age,omitempty"     jsh:"update"`
+	Admin   bool                     `json:"admin"`
+	Group   *jsh.IDObject            `json:"-"                 jsh:"one,create/required,update"`
+	Friends map[string]*jsh.IDObject `json:"-"                 jsh:"many,create"`
+	Teams   map[int]*jsh.IDObject    `json:"-"                 jsh:"many,update"`
+	Address *GeneratedAddress        `json:"address,omitempty" jsh:"create,update"`
+	Labels  map[string]string        `json:"labels,omitempty"  jsh:"create"`
+}
+
+type GeneratedAddress struct {
+	City string `json:"city" jsh:"create,update/required"`
+}
+
+// ReflectedUser has the fields of GeneratedUser without the generated methods.
+type ReflectedUser GeneratedUser
+
+func newGeneratedUser() *GeneratedUser {
+	return &GeneratedUser{
+		Friends: map[string]*jsh.IDObject{},
+		Teams:   map[int]*jsh.IDObject{},
+	}
+}
+
+func TestCodegen(t *testing.T) {
+
+	Convey("Codegen Tests", t, func() {
+
+		So(newGeneratedUser(), ShouldImplement, (*jsh.ObjectValidator)(nil))
+		So(newGeneratedUser(), ShouldImplement, (*jsh.AttributesMarshaler)(nil))
+
+		// process runs ProcessCreate or ProcessUpdate on a generated model and on
+		// the reflection-based validator, which must have the same results.
+		process := func(action, payload string) ([]string, jsh.ErrorList) {
+			generatedObject := &jsh.Object{}
+			So(json.Unmarshal([]byte(payload), generatedObject), ShouldBeNil)
+			reflectedObject := &jsh.Object{}
+			So(json.Unmarshal([]byte(payload), reflectedObject), ShouldBeNil)
+
+			generated := newGeneratedUser()
+			var fields []string
+			var errs jsh.ErrorList
+			if action == "create" {
+				fields, errs = generatedObject.ProcessCreate("users", generated)
+			} else {
+				fields, errs = generatedObject.ProcessUpdate("users", generated)
+			}
+
+			reflected := (*ReflectedUser)(newGeneratedUser())
+			var expectedFields []string
+			expectedErrs := reflectedObject.Unmarshal("users", reflected)
+			if expectedErrs == nil {
+				expectedFields, expectedErrs = jsh.NewValidator(reflectedObject, action).Validate(reflected)
+				if action == "update" && expectedErrs == nil && len(expectedFields) == 0 {
+					expectedErrs = jsh.ErrorList{jsh.BadRequestError("Invalid patch document", "Missing description of changes")}
+				}
+			}
+
+			So(fields, ShouldResemble, expectedFields)
+			So(errs, ShouldResemble, expectedErrs)
+			So(generated, ShouldResemble, (*GeneratedUser)(reflected))
+			return fields, errs
+		}
+
+		Convey("->ValidateObject()", func() {
+
+			Convey("should accept valid objects as the validator", func() {
+				fields, errs := process("create", `{
+					"type": "users",
+					"attributes": {"name": "Jon", "address": {"city": "Paris"}, "labels": {"a": "b"}},
+					"relationships": {
+						"group": {"data": {"type": "groups", "id": "1"}},
+						"friends": {"data": [{"type": "users", "id": "2"}, {"type": "users", "id": "3"}]}
+					}
+				}`)
+				So(errs, ShouldBeNil)
+				So(fields, ShouldResemble, []string{"name", "group", "friends", "address", "address/city", "labels", "labels/a"})
+
+				fields, errs = process("update", `{
+					"type": "users",
+					"id": "1",
+					"attributes": {"age": 32},
+					"relationships": {"teams": {"data": [{"type": "teams", "id": "4"}]}}
+				}`)
+				So(errs, ShouldBeNil)
+				So(fields, ShouldResemble, []string{"age", "teams"})
+			})
+
+			Convey("should reject invalid objects as the validator", func() {
+				_, errs := process("create", `{"type": "users", "attributes": {"age": 32}}`)
+				So(errs, ShouldHaveLength, 3)
+
+				_, errs = process("update", `{"type": "users", "attributes": {"address": {}}}`)
+				So(errs, ShouldHaveLength, 1)
+
+				_, errs = process("update", `{"type": "users", "attributes": {"unknown": 1}}`)
+				So(errs, ShouldHaveLength, 1)
+
+				_, errs = process("update", `{"type": "users", "relationships": {"teams": {"data": [{"type": "teams", "id": "x"}]}}}`)
+				So(errs, ShouldHaveLength, 1)
+
+				_, errs = process("update", `{"type": "users"}`)
+				So(errs, ShouldHaveLength, 1)
+
+				_, errs = process("create", `{"type": "users", "attributes": {"name": "Jon", "email": "jon"}}`)
+				So(errs, ShouldHaveLength, 1)
+			})
+		})
+
+		Convey("->MarshalAttributes()", func() {
+
+			Convey("should marshal attributes as encoding/json", func() {
+				for _, user := range []*GeneratedUser{
+					{Name: "Jon"},
+					{Name: "<Jon>", Email: "jon@example.com", Age: 32, Admin: true, Address: &GeneratedAddress{City: "Paris"}, Labels: map[string]string{"a": "b"}},
+				} {
+					object, err := jsh.NewObject("1", "users", user)
+					So(err, ShouldBeNil)
+					expected, jsonErr := json.Marshal((*ReflectedUser)(user))
+					So(jsonErr, ShouldBeNil)
+					So(string(object.Attributes), ShouldEqual, string(expected))
+				}
+			})
+		})
+	})
+}
@@ -0,0 +1,246 @@
+package jsh
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// Codes of the errors created by the convenience constructors
+const (
+	CodeBadRequest            = "bad_request"
+	CodeInvalidQueryParameter = "invalid_query_parameter"
+	CodeInvalidHeader         = "invalid_header"
+	CodeForbidden             = "forbidden"
+	CodeNotFound              = "not_found"
+	CodeSpecificationError    = "specification_error"
+	CodeNotAcceptable         = "not_acceptable"
+	CodeUnsupportedMediaType  = "unsupported_media_type"
+	CodeConflict              = "conflict"
+	CodeMissingTopLevelMember = "missing_top_level_member"
+	CodeInvalidAttribute      = "invalid_attribute"
+	CodeInvalidRelationship   = "invalid_relationship"
+	CodeInvalidOperation      = "invalid_operation"
+	CodeUnresolvedLinkage     = "unresolved_linkage"
+	CodeInternalServerError   = "internal_server_error"
+	CodeNotImplemented        = "not_implemented"
+)
+
+/*
+ErrorDefinition declares an error code of the API, so that every error with this
+code is consistent and can be documented.
+*/
+type ErrorDefinition struct {
+	// Code is the stable, application-specific error code
+	Code string `json:"code"`
+	// Status is the HTTP Status of the error
+	Status int `json:"status"`
+	// Title is the title template of the error, rendered with fmt.Sprintf
+	Title string `json:"title"`
+	// About is the URL of the page describing the error, set as the about link
+	About string `json:"about,omitempty"`
+	// Description documents when the error occurs, it is not sent to clients
+	Description string `json:"description,omitempty"`
+}
+
+// ErrorRegistry contains the error definitions of an API by code.
+type ErrorRegistry struct {
+	definitions map[string]*ErrorDefinition
+}
+
+// NewErrorRegistry creates an empty error registry.
+func NewErrorRegistry() *ErrorRegistry {
+	return &ErrorRegistry{definitions: map[string]*ErrorDefinition{}}
+}
+
+/*
+ErrorCodes is the default error registry, it contains the definitions of the errors
+created by the convenience constructors, which build their errors from it. Register
+the errors of your application at initialization:
+
+	func init() {
+		jsh.RegisterError(jsh.ErrorDefinition{
+			Code:        "quota_exceeded",
+			Status:      http.StatusTooManyRequests,
+			Title:       "Quota of %d requests exceeded",
+			About:       "https://docs.example.com/errors/quota_exceeded",
+			Description: "The client sent too many requests in the last hour.",
+		})
+	}
+
+	jsh.Send(w, r, jsh.NewError("quota_exceeded", 1000))
+*/
+var ErrorCodes = NewErrorRegistry()
+
+// RegisterError registers the error definition in the default registry, see ErrorRegistry.Register.
+func RegisterError(definition ErrorDefinition) {
+	ErrorCodes.Register(definition)
+}
+
+// NewError creates an error from the default registry, see ErrorRegistry.New.
+func NewError(code string, args ...interface{}) *Error {
+	return ErrorCodes.New(code, args...)
+}
+
+// Register adds the error definition to the registry. As errors are registered at
+// initialization, it panics if the code is empty or already registered, or if the
+// status is not an error status.
+func (r *ErrorRegistry) Register(definition ErrorDefinition) {
+	switch {
+	case definition.Code == "":
+		panic("jsh: error definition without code")
+	case definition.Status < 400 || definition.Status > 600:
+		panic(fmt.Sprintf("jsh: invalid HTTP Status %d for error code '%s'", definition.Status, definition.Code))
+	case r.definitions[definition.Code] != nil:
+		panic(fmt.Sprintf("jsh: error code '%s' is already registered", definition.Code))
+	}
+	r.definitions[definition.Code] = &definition
+}
+
+// Lookup returns the definition of the error code, if registered.
+func (r *ErrorRegistry) Lookup(code string) (*ErrorDefinition, bool) {
+	definition, ok := r.definitions[code]
+	return definition, ok
+}
+
+// Definitions returns every error definition of the registry, sorted by code.
+func (r *ErrorRegistry) Definitions() []*ErrorDefinition {
+	definitions := make([]*ErrorDefinition, 0, len(r.definitions))
+	for _, definition := range r.definitions {
+		definitions = append(definitions, definition)
+	}
+	sort.Slice(definitions, func(i, j int) bool {
+		return definitions[i].Code < definitions[j].Code
+	})
+	return definitions
+}
+
+/*
+New creates an error from the definition of the code: its status, code, about link and
+title rendered with the given arguments. The message key of the title is ErrorTitleKey
+of the code (see Localize). An ISE is returned for unknown codes.
+*/
+func (r *ErrorRegistry) New(code string, args ...interface{}) *Error {
+	definition, ok := r.Lookup(code)
+	if !ok {
+		return ISE(fmt.Sprintf("Unknown error code '%s'", code))
+	}
+
+	err := &Error{
+		Status:    definition.Status,
+		Code:      definition.Code,
+		Title:     definition.Title,
+		TitleKey:  ErrorTitleKey(definition.Code),
+		TitleArgs: args,
+	}
+	if len(args) > 0 {
+		err.Title = fmt.Sprintf(definition.Title, args...)
+	}
+	if definition.About != "" {
+		err.WithAbout(definition.About)
+	}
+	return err
+}
+
+// WriteJSON renders the error definitions of the registry as a JSON array.
+func (r *ErrorRegistry) WriteJSON(w io.Writer) error {
+	content, err := json.MarshalIndent(r.Definitions(), "", " ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(content, '\n'))
+	return err
+}
+
+/*
+WriteMarkdown renders the error definitions of the registry as a Markdown table, to
+include every error clients can receive in the API documentation. For instance, a
+small program of your API run with go generate:
+
+	// Registers the errors of the API as a side effect
+	import _ "example.com/api/errors"
+
+	func main() {
+		if err := jsh.ErrorCodes.WriteMarkdown(os.Stdout); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+The jsherrors command renders the built-in codes and definitions read from a JSON file.
+*/
+func (r *ErrorRegistry) WriteMarkdown(w io.Writer) error {
+	lines := []string{
+		"| Code | Status | Title | Description |",
+		"| ---- | ------ | ----- | ----------- |",
+	}
+	for _, definition := range r.Definitions() {
+		code := fmt.Sprintf("`%s`", definition.Code)
+		if definition.About != "" {
+			code = fmt.Sprintf("[%s](%s)", code, definition.About)
+		}
+		lines = append(lines, fmt.Sprintf(
+			"| %s | %d %s | %s | %s |",
+			code,
+			definition.Status,
+			http.StatusText(definition.Status),
+			markdownCell(definition.Title),
+			markdownCell(definition.Description),
+		))
+	}
+	_, err := io.WriteString(w, strings.Join(lines, "\n")+"\n")
+	return err
+}
+
+// markdownCell escapes the text for a Markdown table cell.
+func markdownCell(text string) string {
+	text = strings.Replace(text, "|", `\|`, -1)
+	return strings.Replace(text, "\n", " ", -1)
+}
+
+// builtinCodes contains the definitions of the built-in codes. The convenience
+// constructors fall back to it if ErrorCodes was replaced by a registry without them.
+var builtinCodes = newBuiltinRegistry()
+
+// newBuiltinError creates an error with a built-in code from its definition in ErrorCodes,
+// so that the errors sent match the documentation generated from the registry.
+func newBuiltinError(code string) *Error {
+	if _, ok := ErrorCodes.Lookup(code); ok {
+		return ErrorCodes.New(code)
+	}
+	return builtinCodes.New(code)
+}
+
+func init() {
+	for _, definition := range builtinCodes.Definitions() {
+		RegisterError(*definition)
+	}
+}
+
+// newBuiltinRegistry returns a registry of the definitions of the built-in codes.
+func newBuiltinRegistry() *ErrorRegistry {
+	registry := NewErrorRegistry()
+	for _, definition := range []ErrorDefinition{
+		{CodeBadRequest, http.StatusBadRequest, "Bad Request", "", "The request is malformed."},
+		{CodeInvalidQueryParameter, http.StatusBadRequest, "Invalid Query Parameter", "", "A query parameter is malformed, unknown or not allowed."},
+		{CodeInvalidHeader, http.StatusBadRequest, "Invalid Header", "", "A request header is malformed or not allowed."},
+		{CodeForbidden, http.StatusForbidden, "Forbidden", "", "The operation is not allowed."},
+		{CodeNotFound, http.StatusNotFound, "Not Found", "", "The requested resource does not exist."},
+		{CodeSpecificationError, http.StatusNotAcceptable, "JSON API Specification Error", "", "The request violates the JSON API specification."},
+		{CodeNotAcceptable, http.StatusNotAcceptable, "Not Acceptable", "", "No media type of the Accept header can be served."},
+		{CodeUnsupportedMediaType, http.StatusUnsupportedMediaType, "Unsupported Media Type", "", "The Content-Type of the request body is not supported."},
+		{CodeConflict, http.StatusConflict, "Resource conflict", "", "The type or ID of the resource does not match the URL."},
+		{CodeMissingTopLevelMember, 422, "Missing top-level member", "", "A mandatory top-level member of the document is missing."},
+		{CodeInvalidAttribute, 422, "Invalid Attribute", "", "An attribute of the resource is missing or invalid."},
+		{CodeInvalidRelationship, 422, "Invalid Relationship", "", "A relationship of the resource is missing or invalid."},
+		{CodeUnresolvedLinkage, 422, "Unresolved Linkage", "", "A resource linkage matches no resource of the compound document."},
+		{CodeInvalidOperation, http.StatusBadRequest, "Invalid Operation", "", "An atomic operation is malformed."},
+		{CodeInternalServerError, http.StatusInternalServerError, "Internal Server Error", "", "An unexpected error occurred on the server."},
+		{CodeNotImplemented, http.StatusNotImplemented, "Not implemented", "", "The operation is not implemented yet."},
+	} {
+		registry.Register(definition)
+	}
+	return registry
+}
@@ -0,0 +1,160 @@
+package jsh
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestErrorCodes(t *testing.T) {
+
+	Convey("Error Code Tests", t, func() {
+
+		registry := NewErrorRegistry()
+		registry.Register(ErrorDefinition{
+			Code:        "quota_exceeded",
+			Status:      http.StatusTooManyRequests,
+			Title:       "Quota of %d requests exceeded",
+			About:       "http://example.com/errors/quota_exceeded",
+			Description: "Too many requests | per hour.",
+		})
+		registry.Register(ErrorDefinition{Code: "gone", Status: http.StatusGone, Title: "Gone"})
+
+		Convey("->Register()", func() {
+
+			Convey("should reject invalid definitions", func() {
+				So(func() { registry.Register(ErrorDefinition{Status: 400}) }, ShouldPanic)
+				So(func() { registry.Register(ErrorDefinition{Code: "ok", Status: 200}) }, ShouldPanic)
+				So(func() { registry.Register(ErrorDefinition{Code: "gone", Status: 410}) }, ShouldPanic)
+			})
+		})
+
+		Convey("->New()", func() {
+
+			Convey("should build an error from its definition", func() {
+				err := registry.New("quota_exceeded", 1000)
+				So(err.Status, ShouldEqual, http.StatusTooManyRequests)
+				So(err.Code, ShouldEqual, "quota_exceeded")
+				So(err.Title, ShouldEqual, "Quota of 1000 requests exceeded")
+				So(err.Links.About.HREF, ShouldEqual, "http://example.com/errors/quota_exceeded")
+				So(err.Validate(nil, true), ShouldBeNil)
+			})
+
+			Convey("should use the title message key of the code", func() {
+				catalog := Catalog{}
+				catalog.Add("fr", map[string]string{ErrorTitleKey("quota_exceeded"): "Quota de %d requêtes dépassé"})
+
+				err := registry.New("quota_exceeded", 1000)
+				So(err.TitleKey, ShouldEqual, "error.quota_exceeded")
+				err.Localize("fr", catalog)
+				So(err.Title, ShouldEqual, "Quota de 1000 requêtes dépassé")
+			})
+
+			Convey("should keep the title template without arguments", func() {
+				err := registry.New("quota_exceeded")
+				So(err.Title, ShouldEqual, "Quota of %d requests exceeded")
+				So(err.TitleArgs, ShouldBeEmpty)
+			})
+
+			Convey("should return an ISE for an unknown code", func() {
+				err := registry.New("unknown")
+				So(err.Status, ShouldEqual, http.StatusInternalServerError)
+			})
+		})
+
+		Convey("built-in codes", func() {
+
+			Convey("should be set by the convenience constructors", func() {
+				So(NotFound("tests", "1").Code, ShouldEqual, CodeNotFound)
+				So(ConflictError("tests", "1").Code, ShouldEqual, CodeConflict)
+				So(InputError("Invalid", "test").Code, ShouldEqual, CodeInvalidAttribute)
+				So(TopLevelError("data").Code, ShouldEqual, CodeMissingTopLevelMember)
+				So(ISE("Failure").Code, ShouldEqual, CodeInternalServerError)
+			})
+
+			Convey("should be registered in the default registry", func() {
+				for _, err := range []*Error{
+					ParameterError("Invalid", "sort"),
+					HeaderError("Invalid", "Accept"),
+					NotFound("tests", "1"),
+					SpecificationError("Invalid"),
+					NotAcceptableError("Invalid"),
+					UnsupportedMediaTypeError("Invalid"),
+					ConflictError("tests", ""),
+					TopLevelError("data"),
+					InputError("Invalid", "test"),
+					RelationshipError("Invalid", "test"),
+					operationError(0, "/op", "Invalid"),
+					ISE("Failure"),
+					NotImplemented("Later"),
+				} {
+					definition, ok := ErrorCodes.Lookup(err.Code)
+					So(ok, ShouldBeTrue)
+					So(definition.Status, ShouldEqual, err.Status)
+					So(err.Title, ShouldEqual, definition.Title)
+					So(err.TitleKey, ShouldEqual, ErrorTitleKey(err.Code))
+				}
+			})
+
+			Convey("should keep the message as title of bad request and forbidden errors", func() {
+				err := BadRequestError("Bad", "Request")
+				So(err.Code, ShouldEqual, CodeBadRequest)
+				So(err.Status, ShouldEqual, http.StatusBadRequest)
+				So(err.Title, ShouldEqual, "Bad")
+				So(err.TitleKey, ShouldBeEmpty)
+				So(err.Detail, ShouldEqual, "Request")
+
+				err = ForbiddenError("Not Allowed")
+				So(err.Code, ShouldEqual, CodeForbidden)
+				So(err.Status, ShouldEqual, http.StatusForbidden)
+				So(err.Title, ShouldEqual, "Not Allowed")
+				So(err.TitleKey, ShouldBeEmpty)
+				So(err.Detail, ShouldBeEmpty)
+			})
+
+			Convey("should use the definitions of the default registry", func() {
+				definition, _ := ErrorCodes.Lookup(CodeNotFound)
+				title := definition.Title
+				definition.Title = "Resource Not Found"
+				defer func() { definition.Title = title }()
+
+				So(NotFound("tests", "1").Title, ShouldEqual, "Resource Not Found")
+			})
+
+			Convey("should not depend on the default registry", func() {
+				codes := ErrorCodes
+				ErrorCodes = NewErrorRegistry()
+				defer func() { ErrorCodes = codes }()
+
+				err := NotFound("tests", "1")
+				So(err.Code, ShouldEqual, CodeNotFound)
+				So(err.Title, ShouldEqual, "Not Found")
+				So(ISE("Failure").Code, ShouldEqual, CodeInternalServerError)
+			})
+		})
+
+		Convey("->WriteMarkdown()", func() {
+			var buffer bytes.Buffer
+			So(registry.WriteMarkdown(&buffer), ShouldBeNil)
+
+			lines := strings.Split(strings.TrimSpace(buffer.String()), "\n")
+			So(lines, ShouldHaveLength, 4)
+			So(lines[2], ShouldEqual, "| `gone` | 410 Gone | Gone |  |")
+			So(lines[3], ShouldEqual, "| [`quota_exceeded`](http://example.com/errors/quota_exceeded) | "+
+				`429 Too Many Requests | Quota of %d requests exceeded | Too many requests \| per hour. |`)
+		})
+
+		Convey("->WriteJSON()", func() {
+			var buffer bytes.Buffer
+			So(registry.WriteJSON(&buffer), ShouldBeNil)
+
+			var definitions []*ErrorDefinition
+			So(json.Unmarshal(buffer.Bytes(), &definitions), ShouldBeNil)
+			So(definitions, ShouldResemble, registry.Definitions())
+		})
+	})
+}
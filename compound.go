@@ -0,0 +1,182 @@
+package jsh
+
+import "fmt"
+
+// resourceKey uniquely identifies a resource within a document by type and either
+// id or local identifier.
+type resourceKey struct {
+	Type string
+	ID   string
+	LID  string
+}
+
+// String returns a human-readable form of the key for error messages.
+func (k resourceKey) String() string {
+	if k.ID == "" && k.LID != "" {
+		return fmt.Sprintf("with lid '%s' (%s)", k.LID, k.Type)
+	}
+	return fmt.Sprintf("'%s' (%s)", k.ID, k.Type)
+}
+
+/*
+BuildCompound creates a compound document with the provided primary data payload
+and candidate included resources: http://jsonapi.org/format/#document-compound-documents
+
+Included resources are deduplicated by type and id. An error is returned if an included
+resource duplicates a primary data resource, or if it is not reachable through the
+relationships of the primary data (full linkage).
+
+	doc, err := jsh.BuildCompound(article, author, comment, commentAuthor)
+	if err != nil {
+		jsh.Send(w, r, err)
+		return
+	}
+
+	jsh.Send(w, r, doc)
+*/
+func BuildCompound(payload Sendable, included ...*Object) (*Document, *Error) {
+	document := Build(payload)
+	if err := document.AddIncluded(included...); err != nil {
+		return nil, err
+	}
+	if err := document.validateLinkage(); err != nil {
+		return nil, err
+	}
+	return document, nil
+}
+
+// AddIncluded adds the objects to the included resources of the document, skipping
+// the ones that were already included. Full linkage is only checked on validation.
+func (d *Document) AddIncluded(objects ...*Object) *Error {
+	if d.Mode == ErrorMode {
+		return ISE("Invalid attempt to add included resources to an error document")
+	}
+
+	included := map[resourceKey]bool{}
+	for _, object := range d.Included {
+		included[object.key()] = true
+	}
+	primary := d.primaryKeys()
+	for _, object := range objects {
+		if !object.identified() {
+			return ISE(fmt.Sprintf("Included resource of type '%s' has neither an ID nor a lid", object.Type))
+		}
+		key := object.key()
+		if primary[key] {
+			return ISE(fmt.Sprintf("Included resource %s duplicates primary data", key))
+		}
+		if included[key] {
+			continue
+		}
+		included[key] = true
+		d.Included = append(d.Included, object)
+	}
+	return nil
+}
+
+// validateIncluded checks that the included resources follow the compound document rules:
+// no duplicate, no duplication of the primary data, and full linkage.
+func (d *Document) validateIncluded() *Error {
+	included := map[resourceKey]bool{}
+	primary := d.primaryKeys()
+	for _, object := range d.Included {
+		key := object.key()
+		if primary[key] {
+			return ISE(fmt.Sprintf("Included resource %s duplicates primary data", key))
+		}
+		if included[key] {
+			return ISE(fmt.Sprintf("Included resource %s is included more than once", key))
+		}
+		included[key] = true
+	}
+	return d.validateLinkage()
+}
+
+// validateLinkage checks that every included resource is reachable from the primary data
+// by following relationship linkage through the primary data and included resources.
+func (d *Document) validateLinkage() *Error {
+	linked := map[resourceKey]bool{}
+	for _, object := range d.Data {
+		addLinkageKeys(linked, object)
+	}
+	return validateLinkage(d.Included, linked)
+}
+
+// validateLinkage checks that every included resource is reachable from the linked
+// resources by following relationship linkage through the included resources.
+func validateLinkage(includedObjects []*Object, linked map[resourceKey]bool) *Error {
+	included := map[resourceKey]*Object{}
+	for _, object := range includedObjects {
+		included[object.key()] = object
+	}
+
+	// Walk the relationship graph starting from the resources linked by the primary data
+	reached := map[resourceKey]bool{}
+	var queue List
+	for key := range linked {
+		if next, ok := included[key]; ok {
+			reached[key] = true
+			queue = append(queue, next)
+		}
+	}
+	for len(queue) > 0 {
+		object := queue[0]
+		queue = queue[1:]
+		for _, relationship := range object.Relationships {
+			if relationship == nil {
+				continue
+			}
+			for _, linkage := range relationship.Data {
+				key := linkage.key()
+				next, ok := included[key]
+				if !ok || reached[key] {
+					continue
+				}
+				reached[key] = true
+				queue = append(queue, next)
+			}
+		}
+	}
+
+	for _, object := range includedObjects {
+		if key := object.key(); !reached[key] {
+			return ISE(fmt.Sprintf("Included resource %s is not linked from primary data", key))
+		}
+	}
+	return nil
+}
+
+// addLinkageKeys adds the keys of the resources linked by the relationships of the object.
+func addLinkageKeys(keys map[resourceKey]bool, object *Object) {
+	for _, relationship := range object.Relationships {
+		if relationship == nil {
+			continue
+		}
+		for _, linkage := range relationship.Data {
+			keys[linkage.key()] = true
+		}
+	}
+}
+
+// primaryKeys returns the keys of the primary data resources.
+func (d *Document) primaryKeys() map[resourceKey]bool {
+	keys := map[resourceKey]bool{}
+	for _, object := range d.Data {
+		keys[object.key()] = true
+	}
+	return keys
+}
+
+// key returns the key identifying the object within a document.
+func (o *Object) key() resourceKey {
+	return o.ToIDObject().key()
+}
+
+// key returns the key identifying the resource within a document, the ID takes
+// precedence over the local identifier.
+func (obj *IDObject) key() resourceKey {
+	if obj.ID != "" {
+		return resourceKey{Type: obj.Type, ID: obj.ID}
+	}
+	return resourceKey{Type: obj.Type, LID: obj.LID}
+}
@@ -0,0 +1,90 @@
+package jsh
+
+import (
+	"net/http"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestCompoundDocument(t *testing.T) {
+
+	Convey("Compound Document Tests", t, func() {
+
+		article, err := NewObject("1", "articles", nil)
+		So(err, ShouldBeNil)
+		author, err := NewObject("9", "people", nil)
+		So(err, ShouldBeNil)
+		comment, err := NewObject("5", "comments", nil)
+		So(err, ShouldBeNil)
+
+		article.AddRelationshipOne("author", author.ToIDObject())
+		article.AddRelationshipMany("comments", IDList{comment.ToIDObject()})
+
+		Convey("->BuildCompound()", func() {
+
+			Convey("should deduplicate included resources", func() {
+				req := &http.Request{Method: "GET"}
+				So(article.Validate(req, true), ShouldBeNil)
+
+				doc, err := BuildCompound(article, author, comment, author)
+				So(err, ShouldBeNil)
+				So(doc.Included, ShouldResemble, []*Object{author, comment})
+				So(doc.Validate(req, true), ShouldBeNil)
+			})
+
+			Convey("should accept resources linked through included resources", func() {
+				commenter, err := NewObject("2", "people", nil)
+				So(err, ShouldBeNil)
+				comment.AddRelationshipOne("author", commenter.ToIDObject())
+
+				doc, err := BuildCompound(List{article}, commenter, comment)
+				So(err, ShouldBeNil)
+				So(doc.Included, ShouldHaveLength, 2)
+			})
+
+			Convey("should reject an included resource duplicating primary data", func() {
+				_, err := BuildCompound(article, &Object{ID: "1", Type: "articles"})
+				So(err, ShouldNotBeNil)
+				So(err.Status, ShouldEqual, http.StatusInternalServerError)
+			})
+
+			Convey("should reject an orphan included resource", func() {
+				orphan, err := NewObject("3", "people", nil)
+				So(err, ShouldBeNil)
+
+				_, buildErr := BuildCompound(article, author, orphan)
+				So(buildErr, ShouldNotBeNil)
+			})
+		})
+
+		Convey("->AddIncluded()", func() {
+
+			Convey("should accept resources identified by a lid", func() {
+				draft := &Object{Type: "people", LID: "draft"}
+				article.AddRelationshipOne("editor", &IDObject{Type: "people", LID: "draft"})
+
+				doc := Build(article)
+				So(doc.AddIncluded(draft, draft), ShouldBeNil)
+				So(doc.Included, ShouldResemble, []*Object{draft})
+			})
+
+			Convey("should reject resources without an ID or a lid", func() {
+				err := Build(article).AddIncluded(&Object{Type: "people"})
+				So(err, ShouldNotBeNil)
+				So(err.Status, ShouldEqual, http.StatusInternalServerError)
+			})
+		})
+
+		Convey("->Validate()", func() {
+
+			Convey("should reject duplicate included resources", func() {
+				doc := Build(article)
+				doc.Included = []*Object{author, author}
+
+				err := doc.Validate(&http.Request{Method: "GET"}, true)
+				So(err, ShouldNotBeNil)
+			})
+		})
+	})
+}
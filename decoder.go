@@ -0,0 +1,281 @@
+package jsh
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+/*
+DocumentDecoder decodes a document from a reader, yielding the resource objects of
+its primary data one at a time instead of decoding the whole document in memory.
+Each object is validated as Parser.Document does.
+
+	decoder, err := parser.Stream(body)
+	if err != nil {
+		return err
+	}
+	defer decoder.Close()
+
+	for {
+		object, err := decoder.Next()
+		if err != nil {
+			return err
+		}
+		if object == nil {
+			break
+		}
+		// process object
+	}
+	meta := decoder.Document().Meta
+
+The other top-level members ("meta", "links", "included", "errors"...) are decoded to
+Document as they are read: the members sent before "data" are available after the
+first call to Next, and every member is available once Next returned nil. The data of
+Document is always empty. DocumentDecoder implements ObjectStream, so that a decoded
+document can be streamed to SendStream.
+*/
+type DocumentDecoder struct {
+	method   string
+	reader   io.ReadCloser
+	decoder  *json.Decoder
+	document *Document
+	// inList is true while decoding the items of the data array
+	inList bool
+	// pending is a single resource object of the data member, yet to be returned
+	pending *Object
+	index   int
+	done    bool
+	err     *Error
+}
+
+/*
+Stream returns a decoder of the document of the payload, see DocumentDecoder. The
+headers are validated as for Document, and the payload is closed at the end of the
+document, on error, or by DocumentDecoder.Close.
+*/
+func (p *Parser) Stream(payload io.ReadCloser) (*DocumentDecoder, *Error) {
+	if err := validateHeaders(p.Headers); err != nil {
+		closeReader(payload)
+		return nil, err
+	}
+
+	d := &DocumentDecoder{
+		method:   p.Method,
+		reader:   payload,
+		decoder:  json.NewDecoder(payload),
+		document: &Document{Data: List{}},
+	}
+	if err := d.expectDelim('{'); err != nil {
+		d.fail(err)
+		return nil, err
+	}
+	return d, nil
+}
+
+// Document returns the top-level members decoded so far.
+func (d *DocumentDecoder) Document() *Document {
+	return d.document
+}
+
+// Next returns the next resource object of the primary data, or nil once the whole
+// document has been decoded.
+func (d *DocumentDecoder) Next() (*Object, *Error) {
+	if d.err != nil {
+		return nil, d.err
+	}
+	if d.done {
+		return nil, nil
+	}
+
+	object, err := d.next()
+	if err != nil {
+		d.fail(err)
+		return nil, err
+	}
+	if object == nil {
+		d.done = true
+		closeReader(d.reader)
+		return nil, nil
+	}
+	if err := d.validate(object); err != nil {
+		d.fail(err)
+		return nil, err
+	}
+	d.index++
+	return object, nil
+}
+
+// Close stops the decoding and closes the payload. It can be called at any time.
+func (d *DocumentDecoder) Close() error {
+	if d.done || d.err != nil {
+		return nil
+	}
+	d.done = true
+	return d.reader.Close()
+}
+
+// next decodes the top-level members until the next object of the primary data.
+func (d *DocumentDecoder) next() (*Object, *Error) {
+	if d.pending != nil {
+		object := d.pending
+		d.pending = nil
+		return object, nil
+	}
+
+	for {
+		if d.inList {
+			if d.decoder.More() {
+				return d.decodeObject()
+			}
+			// Consume the end of the data array
+			if err := d.expectDelim(']'); err != nil {
+				return nil, err
+			}
+			d.inList = false
+		}
+
+		if !d.decoder.More() {
+			if err := d.expectDelim('}'); err != nil {
+				return nil, err
+			}
+			return nil, nil
+		}
+
+		token, err := d.decoder.Token()
+		if err != nil {
+			return nil, invalidDocumentError(err)
+		}
+		member, ok := token.(string)
+		if !ok {
+			return nil, BadRequestError("Invalid JSON Document", fmt.Sprintf("Unexpected token %v", token))
+		}
+		if member != "data" {
+			if err := d.decodeMember(member); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		object, dataErr := d.decodeData()
+		if dataErr != nil || object != nil {
+			return object, dataErr
+		}
+	}
+}
+
+// decodeData starts the decoding of the data member. A single resource object is
+// returned directly.
+func (d *DocumentDecoder) decodeData() (*Object, *Error) {
+	token, err := d.decoder.Token()
+	if err != nil {
+		return nil, invalidDocumentError(err)
+	}
+	switch token {
+	case json.Delim('['):
+		d.document.Mode = ListMode
+		d.inList = true
+		return nil, nil
+	case nil:
+		d.document.Mode = ObjectMode
+		return nil, nil
+	case json.Delim('{'):
+		// The object token was consumed: decode its members to a raw object
+		d.document.Mode = ObjectMode
+		raw := json.RawMessage{'{'}
+		for d.decoder.More() {
+			key, err := d.decoder.Token()
+			if err != nil {
+				return nil, invalidDocumentError(err)
+			}
+			var value json.RawMessage
+			if err := d.decoder.Decode(&value); err != nil {
+				return nil, invalidDocumentError(err)
+			}
+			name, _ := json.Marshal(key)
+			if len(raw) > 1 {
+				raw = append(raw, ',')
+			}
+			raw = append(append(append(raw, name...), ':'), value...)
+		}
+		if err := d.expectDelim('}'); err != nil {
+			return nil, err
+		}
+		raw = append(raw, '}')
+		object := &Object{}
+		if err := json.Unmarshal(raw, object); err != nil {
+			return nil, invalidDocumentError(err)
+		}
+		return object, nil
+	}
+	return nil, BadRequestError("Invalid JSON Document", fmt.Sprintf("Unexpected data token %v", token))
+}
+
+// decodeObject decodes the next object of the data array.
+func (d *DocumentDecoder) decodeObject() (*Object, *Error) {
+	object := &Object{}
+	if err := d.decoder.Decode(object); err != nil {
+		return nil, invalidDocumentError(err)
+	}
+	return object, nil
+}
+
+// decodeMember decodes a top-level member other than data to the document.
+func (d *DocumentDecoder) decodeMember(member string) *Error {
+	var value json.RawMessage
+	if err := d.decoder.Decode(&value); err != nil {
+		return invalidDocumentError(err)
+	}
+	name, _ := json.Marshal(member)
+	content := append(append(append([]byte{'{'}, name...), ':'), value...)
+	content = append(content, '}')
+	if err := json.Unmarshal(content, d.document); err != nil {
+		return invalidDocumentError(err)
+	}
+	return nil
+}
+
+// validate validates the object against the specification, as Parser.Document does.
+func (d *DocumentDecoder) validate(object *Object) *Error {
+	if errlist := validateInput(object); errlist != nil {
+		return errlist[0]
+	}
+	if errlist := validateRelationships(object); errlist != nil {
+		return errlist[0]
+	}
+	// Resource objects of a list of more than one object must have IDs
+	inList := d.document.Mode == ListMode && (d.index > 0 || d.decoder.More())
+	if inList && !object.identifiedFor(d.method) {
+		return InputError("Object without ID present in list", "id")
+	}
+	return nil
+}
+
+// expectDelim consumes the next token, which must be the given delimiter.
+func (d *DocumentDecoder) expectDelim(delim json.Delim) *Error {
+	token, err := d.decoder.Token()
+	if err != nil {
+		return invalidDocumentError(err)
+	}
+	if token != delim {
+		return BadRequestError("Invalid JSON Document", fmt.Sprintf("Expected %v, got %v", delim, token))
+	}
+	return nil
+}
+
+// fail records the error, which is returned by every following call to Next, and
+// closes the payload.
+func (d *DocumentDecoder) fail(err *Error) {
+	if d.err == nil && !d.done {
+		closeReader(d.reader)
+	}
+	d.err = err
+}
+
+// invalidDocumentError converts a decoding error to a jsh error.
+func invalidDocumentError(err error) *Error {
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	return BadRequestError("Invalid JSON Document", err.Error())
+}
@@ -0,0 +1,132 @@
+package jsh
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestDecoder(t *testing.T) {
+
+	Convey("Decoder Tests", t, func() {
+
+		parser := &Parser{Headers: http.Header{"Content-Type": []string{ContentType}}}
+		stream := func(payload string) *DocumentDecoder {
+			decoder, err := parser.Stream(CreateReadCloser([]byte(payload)))
+			So(err, ShouldBeNil)
+			return decoder
+		}
+		decodeAll := func(decoder *DocumentDecoder) (List, *Error) {
+			list := List{}
+			for {
+				object, err := decoder.Next()
+				if err != nil || object == nil {
+					return list, err
+				}
+				list = append(list, object)
+			}
+		}
+
+		Convey("->Stream()", func() {
+
+			Convey("should decode the objects of a list one at a time", func() {
+				decoder := stream(`{
+					"meta": {"total": 2},
+					"data": [
+						{"type": "users", "id": "1", "attributes": {"name": "Jon"}},
+						{"type": "users", "id": "2", "relationships": {"group": {"data": {"type": "groups", "id": "1"}}}}
+					],
+					"included": [{"type": "groups", "id": "1"}],
+					"links": {"next": "/users?page[number]=2"}
+				}`)
+
+				object, err := decoder.Next()
+				So(err, ShouldBeNil)
+				So(object.ID, ShouldEqual, "1")
+				So(string(object.Attributes), ShouldEqual, `{"name": "Jon"}`)
+				So(decoder.Document().Mode, ShouldEqual, ListMode)
+				So(decoder.Document().Meta, ShouldResemble, map[string]interface{}{"total": float64(2)})
+				So(decoder.Document().Included, ShouldBeNil)
+
+				object, err = decoder.Next()
+				So(err, ShouldBeNil)
+				So(object.Relationships["group"].Data[0].ID, ShouldEqual, "1")
+
+				object, err = decoder.Next()
+				So(err, ShouldBeNil)
+				So(object, ShouldBeNil)
+				So(decoder.Document().Included, ShouldHaveLength, 1)
+				So(decoder.Document().Links.Next.HREF, ShouldEqual, "/users?page[number]=2")
+				So(decoder.Document().Data, ShouldBeEmpty)
+
+				object, err = decoder.Next()
+				So(object, ShouldBeNil)
+				So(err, ShouldBeNil)
+				So(decoder.Close(), ShouldBeNil)
+			})
+
+			Convey("should decode a single object", func() {
+				decoder := stream(`{"data": {"type": "users", "attributes": {"name": "Jon"}}, "meta": {"a": 1}}`)
+				list, err := decodeAll(decoder)
+				So(err, ShouldBeNil)
+				So(list, ShouldHaveLength, 1)
+				So(list[0].Type, ShouldEqual, "users")
+				So(decoder.Document().Mode, ShouldEqual, ObjectMode)
+				So(decoder.Document().Meta, ShouldNotBeNil)
+
+				list, err = decodeAll(stream(`{"data": null}`))
+				So(err, ShouldBeNil)
+				So(list, ShouldBeEmpty)
+			})
+
+			Convey("should decode error documents", func() {
+				decoder := stream(`{"errors": [{"status": "404", "title": "Not Found"}]}`)
+				list, err := decodeAll(decoder)
+				So(err, ShouldBeNil)
+				So(list, ShouldBeEmpty)
+				So(decoder.Document().HasErrors(), ShouldBeTrue)
+			})
+
+			Convey("should validate objects as they are decoded", func() {
+				decoder := stream(`{"data": [{"type": "users", "id": "1"}, {"type": "users"}]}`)
+				object, err := decoder.Next()
+				So(err, ShouldBeNil)
+				So(object.ID, ShouldEqual, "1")
+
+				_, err = decoder.Next()
+				So(err, ShouldNotBeNil)
+				So(err.Status, ShouldEqual, 422)
+
+				_, sameErr := decoder.Next()
+				So(sameErr, ShouldEqual, err)
+			})
+
+			Convey("should reject invalid documents", func() {
+				_, err := decodeAll(stream(`{"data": [{"type": "users", "id": "1"}`))
+				So(err, ShouldNotBeNil)
+				So(err.Status, ShouldEqual, http.StatusBadRequest)
+
+				_, streamErr := parser.Stream(CreateReadCloser([]byte(`[]`)))
+				So(streamErr, ShouldNotBeNil)
+
+				parser.Headers.Set("Content-Type", "application/json")
+				_, streamErr = parser.Stream(CreateReadCloser([]byte(`{}`)))
+				So(streamErr, ShouldNotBeNil)
+				So(streamErr.Status, ShouldEqual, http.StatusUnsupportedMediaType)
+			})
+
+			Convey("should stream a decoded document to SendStream", func() {
+				decoder := stream(`{"data": [{"type": "users", "id": "1"}, {"type": "users", "id": "2"}]}`)
+				writer := httptest.NewRecorder()
+				So(SendStream(writer, &http.Request{Method: "GET"}, &Document{}, decoder), ShouldBeNil)
+
+				result := &Document{Mode: ListMode}
+				So(json.Unmarshal(writer.Body.Bytes(), result), ShouldBeNil)
+				So(result.Data, ShouldHaveLength, 2)
+			})
+		})
+	})
+}
@@ -18,6 +18,10 @@ const (
 	ListMode
 	// ErrorMode enforces error response specifications
 	ErrorMode
+	// AtomicMode enforces Atomic Operations extension request/response specifications
+	AtomicMode
+	// MetaMode enforces meta-only document specifications, without primary data
+	MetaMode
 )
 
 // IncludeJSONAPIVersion is an option that allows consumers to include/remove the `jsonapi`
@@ -25,9 +29,13 @@ const (
 var IncludeJSONAPIVersion = true
 
 // JSONAPI is the top-level member of a JSONAPI document that includes
-// the server compatible version of the JSONAPI specification.
+// the server compatible version of the JSONAPI specification, and the
+// extensions and profiles applied to the document.
 type JSONAPI struct {
-	Version string `json:"version"`
+	Version string                 `json:"version"`
+	Ext     []string               `json:"ext,omitempty"`
+	Profile []string               `json:"profile,omitempty"`
+	Meta    map[string]interface{} `json:"meta,omitempty"`
 }
 
 /*
@@ -43,6 +51,9 @@ type Document struct {
 	Included []*Object   `json:"included,omitempty"`
 	Meta     interface{} `json:"meta,omitempty"`
 	JSONAPI  *JSONAPI    `json:"jsonapi,omitempty"`
+	// Operations and Results are the top-level members of the Atomic Operations extension
+	Operations []*Operation  `json:"atomic:operations,omitempty"`
+	Results    AtomicResults `json:"atomic:results,omitempty"`
 	// Status is an HTTP Status Code
 	Status int `json:"-"`
 	// DataMode to enforce for the document
@@ -73,7 +84,7 @@ func New() *Document {
 /*
 Build creates a Sendable Document with the provided sendable payload, either Data or
 errors. Build also assumes you've already validated your data with .Validate() so
-it should be used carefully.
+it should be used carefully. Sparse fieldsets are applied by Send, see Document.Prune.
 */
 func Build(payload Sendable) *Document {
 	document := New()
@@ -107,9 +118,21 @@ func Build(payload Sendable) *Document {
 		document.Status = p.Status
 		document.Mode = ErrorMode
 	case ErrorList:
+		if len(p) == 0 {
+			p = ErrorList{ISE("Attempting to build a document from an empty error list")}
+		}
 		document.Errors = p
-		document.Status = p[0].Status
+		document.Status = p.StatusCode()
 		document.Mode = ErrorMode
+	case AtomicResults:
+		document.Results = p
+		document.Status = http.StatusOK
+		document.Mode = AtomicMode
+	case Meta:
+		document.Data = nil
+		document.Meta = p
+		document.Status = http.StatusOK
+		document.Mode = MetaMode
 	}
 	return document
 }
@@ -152,18 +175,40 @@ func (d *Document) Validate(r *http.Request, isResponse bool) *Error {
 		if !d.HasErrors() && d.Data == nil {
 			return ISE("Data cannot be nil in 'ListMode', use empty array")
 		}
+	case AtomicMode:
+		if d.HasData() {
+			return ISE("Attempting to respond with 'data' in an atomic operations response")
+		}
+	case MetaMode:
+		if d.HasData() {
+			return ISE("Attempting to respond with 'data' in a meta-only document")
+		}
+		if d.Meta == nil {
+			return ISE("Meta cannot be nil in 'MetaMode'")
+		}
 	}
 
 	if !d.HasData() && d.Included != nil {
 		return ISE("'included' should only be set for a response if 'data' is as well")
 	}
 
+	if err := d.validateIncluded(); err != nil {
+		return err
+	}
+
 	err := d.Data.Validate(r, isResponse)
 	if err != nil {
 		return err
 	}
 
-	err = d.Errors.Validate(r, isResponse)
+	if d.HasErrors() {
+		err = d.Errors.Validate(r, isResponse)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = d.Results.Validate(r, isResponse)
 	if err != nil {
 		return err
 	}
@@ -202,12 +247,10 @@ func (d *Document) AddError(newErr *Error) *Error {
 	if newErr.Status == 0 {
 		return ISE("No HTTP Status code provided for error, cannot add to document")
 	}
-	if d.Status == 0 {
-		d.Status = newErr.Status
-	}
 
-	// set document to error mode
+	// set document to error mode, the status accounts for every error of the document
 	d.Errors = append(d.Errors, newErr)
+	d.Status = d.Errors.StatusCode()
 	d.Mode = ErrorMode
 	return nil
 }
@@ -270,10 +313,10 @@ func (d *Document) MarshalJSON() ([]byte, error) {
 			Data:       data,
 		})
 
-	case ErrorMode:
-		// subtype that omits data as expected for error responses. We cannot simply
-		// use json:"-" for the data attribute otherwise it will not override the
-		// default struct tag of it the composed MarshalDoc struct.
+	case ErrorMode, AtomicMode, MetaMode:
+		// subtype that omits data as expected for error, atomic operations and meta-only documents.
+		// We cannot simply use json:"-" for the data attribute otherwise it will not
+		// override the default struct tag of it the composed MarshalDoc struct.
 		type MarshalError struct {
 			MarshalDoc
 			Data *Object `json:"data,omitempty"`
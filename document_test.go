@@ -150,6 +150,23 @@ func TestDocument(t *testing.T) {
 				So(doc.Status, ShouldEqual, err.Status)
 				So(doc.Mode, ShouldEqual, ErrorMode)
 			})
+
+			Convey("should not panic for an empty error list", func() {
+				doc := Build(ErrorList{})
+
+				So(doc.Errors, ShouldHaveLength, 1)
+				So(doc.Status, ShouldEqual, http.StatusInternalServerError)
+				So(doc.Mode, ShouldEqual, ErrorMode)
+			})
+
+			Convey("should accept meta", func() {
+				doc := Build(Meta{"count": 2})
+
+				So(doc.HasData(), ShouldBeFalse)
+				So(doc.Meta, ShouldResemble, Meta{"count": 2})
+				So(doc.Status, ShouldEqual, http.StatusOK)
+				So(doc.Mode, ShouldEqual, MetaMode)
+			})
 		})
 
 		Convey("->Validate()", func() {
@@ -167,6 +184,17 @@ func TestDocument(t *testing.T) {
 
 			req := &http.Request{Method: "GET"}
 
+			Convey("should require meta without data in MetaMode", func() {
+				doc := NewMetaDocument(nil)
+				So(doc.Validate(req, true), ShouldNotBeNil)
+
+				doc = NewMetaDocument(Meta{"count": 2})
+				So(doc.Validate(req, true), ShouldBeNil)
+
+				doc.Data = List{testObject}
+				So(doc.Validate(req, true), ShouldNotBeNil)
+			})
+
 			Convey("should not accept an included object without objects in data", func() {
 				doc := New()
 				doc.Included = append(doc.Included, testObjectForInclusion)
@@ -178,6 +206,9 @@ func TestDocument(t *testing.T) {
 			})
 
 			Convey("should accept an object in data and an included object", func() {
+				testObject.Relationships = map[string]*Relationship{
+					"inclusion": {Data: IDList{testObjectForInclusion.ToIDObject()}},
+				}
 				doc := Build(testObject)
 				doc.Included = append(doc.Included, testObjectForInclusion)
 
@@ -190,6 +221,21 @@ func TestDocument(t *testing.T) {
 				So(doc.Status, ShouldEqual, http.StatusAccepted)
 			})
 
+			Convey("should not accept an included object that is not linked from data", func() {
+				doc := Build(testObject)
+				doc.Included = append(doc.Included, testObjectForInclusion)
+
+				validationErrors := doc.Validate(req, true)
+				So(validationErrors, ShouldNotBeNil)
+			})
+
+			Convey("should not accept an included object duplicating data", func() {
+				doc := Build(testObject)
+				doc.Included = append(doc.Included, &Object{ID: testObject.ID, Type: testObject.Type})
+
+				validationErrors := doc.Validate(req, true)
+				So(validationErrors, ShouldNotBeNil)
+			})
 		})
 	})
 }
@@ -314,6 +360,22 @@ func TestDocumentMarshaling(t *testing.T) {
 					So(errors, ShouldEndWith, "]")
 				})
 			})
+
+			Convey("MetaMode", func() {
+
+				Convey("should only include 'meta' field for meta-only documents", func() {
+					rawJSON, err := json.Marshal(NewMetaDocument(Meta{"count": 2}))
+					So(err, ShouldBeNil)
+
+					jMap := map[string]json.RawMessage{}
+					err = json.Unmarshal(rawJSON, &jMap)
+					So(err, ShouldBeNil)
+
+					_, exists := jMap["data"]
+					So(exists, ShouldBeFalse)
+					So(string(jMap["meta"]), ShouldEqual, `{"count":2}`)
+				})
+			})
 		})
 	})
 }
@@ -0,0 +1,92 @@
+package jsh
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+)
+
+// Encoder configures the formatting of the JSON documents sent by jsh. The zero
+// value produces compact JSON, setting Prefix or Indent produces indented JSON as
+// json.MarshalIndent does.
+type Encoder struct {
+	Prefix string
+	Indent string
+}
+
+var (
+	// CompactEncoder produces compact JSON, without insignificant whitespace
+	CompactEncoder = Encoder{}
+	// IndentedEncoder produces JSON indented with a single space
+	IndentedEncoder = Encoder{Indent: " "}
+)
+
+/*
+DefaultEncoder is the encoder used to send documents. Documents are compact by default,
+configure it at initialization to indent every response:
+
+	jsh.DefaultEncoder = jsh.Encoder{Indent: "\t"}
+*/
+var DefaultEncoder = CompactEncoder
+
+/*
+PrettyEncoder is the encoder used for the requests asking for a human readable
+response, with the PrettyParameter query parameter or the PrettyHeader header:
+
+	GET /articles?pretty_print
+	GET /articles?pretty_print=true
+	X-Pretty-Print: true
+
+Set PrettyParameter or PrettyHeader to an empty string to disable it. As the JSON API
+specification reserves the query parameters made of lowercase letters only, a custom
+PrettyParameter must contain another character, e.g. "_pretty".
+*/
+var PrettyEncoder = IndentedEncoder
+
+var (
+	// PrettyParameter is the implementation-specific query parameter asking for an indented response
+	PrettyParameter = "pretty_print"
+	// PrettyHeader is the request header asking for an indented response, useful to debug
+	PrettyHeader = "X-Pretty-Print"
+)
+
+// Indented returns true if the encoder produces indented JSON.
+func (e Encoder) Indented() bool {
+	return e.Prefix != "" || e.Indent != ""
+}
+
+// Marshal returns the JSON encoding of v formatted by the encoder.
+func (e Encoder) Marshal(v interface{}) ([]byte, error) {
+	if !e.Indented() {
+		return json.Marshal(v)
+	}
+	return json.MarshalIndent(v, e.Prefix, e.Indent)
+}
+
+// RequestEncoder returns the encoder of the response to the request: PrettyEncoder if
+// the client asked for a human readable response, DefaultEncoder otherwise.
+func RequestEncoder(r *http.Request) Encoder {
+	if r == nil {
+		return DefaultEncoder
+	}
+	if PrettyParameter != "" {
+		if values, ok := query(r)[PrettyParameter]; ok && isPretty(values[0]) {
+			return PrettyEncoder
+		}
+	}
+	if PrettyHeader != "" {
+		if values, ok := r.Header[http.CanonicalHeaderKey(PrettyHeader)]; ok && isPretty(values[0]) {
+			return PrettyEncoder
+		}
+	}
+	return DefaultEncoder
+}
+
+// isPretty returns true if the value of the pretty parameter or header is empty or true.
+func isPretty(value string) bool {
+	if value == "" {
+		return true
+	}
+	pretty, err := strconv.ParseBool(value)
+	return err == nil && pretty
+}
@@ -0,0 +1,87 @@
+package jsh
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestEncoding(t *testing.T) {
+
+	Convey("Encoding Tests", t, func() {
+
+		object, objErr := NewObject("1", "articles", map[string]string{"title": "JSON API"})
+		So(objErr, ShouldBeNil)
+
+		send := func(target string, header string) *httptest.ResponseRecorder {
+			req, err := http.NewRequest("GET", target, nil)
+			So(err, ShouldBeNil)
+			if header != "" {
+				req.Header.Set(PrettyHeader, header)
+			}
+			writer := httptest.NewRecorder()
+			So(Send(writer, req, object), ShouldBeNil)
+			return writer
+		}
+
+		compact := `{"jsonapi":{"version":"1.1"},"data":{"type":"articles","id":"1","attributes":{"title":"JSON API"}}}`
+		indented := "{\n \"jsonapi\": {\n  \"version\": \"1.1\"\n },\n \"data\": {\n  \"type\": \"articles\",\n  \"id\": \"1\",\n  \"attributes\": {\n   \"title\": \"JSON API\"\n  }\n }\n}"
+
+		Convey("->Marshal()", func() {
+
+			Convey("should marshal compact JSON by default", func() {
+				content, err := Encoder{}.Marshal(object)
+				So(err, ShouldBeNil)
+				So(string(content), ShouldEqual, `{"type":"articles","id":"1","attributes":{"title":"JSON API"}}`)
+			})
+
+			Convey("should indent JSON with a custom indent", func() {
+				content, err := Encoder{Prefix: ">", Indent: "\t"}.Marshal(map[string]int{"count": 1})
+				So(err, ShouldBeNil)
+				So(string(content), ShouldEqual, "{\n>\t\"count\": 1\n>}")
+			})
+		})
+
+		Convey("->RequestEncoder()", func() {
+
+			Convey("should send compact documents by default", func() {
+				writer := send("/articles/1", "")
+				So(writer.Body.String(), ShouldEqual, compact)
+				So(writer.Header().Get("Content-Length"), ShouldEqual, strconv.Itoa(len(compact)))
+			})
+
+			Convey("should send indented documents with the pretty parameter", func() {
+				So(send("/articles/1?pretty_print", "").Body.String(), ShouldEqual, indented)
+				So(send("/articles/1?pretty_print=true", "").Body.String(), ShouldEqual, indented)
+				So(send("/articles/1?pretty_print=false", "").Body.String(), ShouldEqual, compact)
+				So(send("/articles/1?pretty", "").Body.String(), ShouldEqual, compact)
+			})
+
+			Convey("should send indented documents with the pretty header", func() {
+				So(send("/articles/1", "true").Body.String(), ShouldEqual, indented)
+				So(send("/articles/1", "0").Body.String(), ShouldEqual, compact)
+			})
+
+			Convey("should ignore disabled pretty parameters", func() {
+				PrettyParameter = ""
+				defer func() { PrettyParameter = "pretty_print" }()
+				So(send("/articles/1?pretty_print", "").Body.String(), ShouldEqual, compact)
+			})
+
+			Convey("should use the configured encoders", func() {
+				DefaultEncoder = Encoder{Indent: "\t"}
+				PrettyEncoder = Encoder{Indent: "    "}
+				defer func() {
+					DefaultEncoder = CompactEncoder
+					PrettyEncoder = IndentedEncoder
+				}()
+
+				So(send("/articles/1", "").Body.String(), ShouldStartWith, "{\n\t\"jsonapi\"")
+				So(send("/articles/1?pretty_print", "").Body.String(), ShouldStartWith, "{\n    \"jsonapi\"")
+			})
+		})
+	})
+}
@@ -14,7 +14,8 @@ a returned jsh.Error before sending it as a response as well.
 */
 var DefaultErrorDetail = "Request failed, something went wrong"
 
-// DefaultTitle can be customized to provide a more customized ISE Title
+// DefaultTitle can be customized to provide a more customized ISE Title, it replaces
+// the title of the internal_server_error definition of ErrorCodes.
 var DefaultErrorTitle = "Internal Server Error"
 
 /*
@@ -27,7 +28,8 @@ type ErrorType interface {
 	Error() string
 	// Validate checks that the error is valid in the context of JSONAPI
 	Validate(r *http.Request, response bool) *Error
-	// StatusCode returns the first encountered HTTP Status Code for the error type.
+	// StatusCode returns the HTTP Status Code of the response for the error type: the
+	// status of an Error, or the status computed by ErrorStatusPolicy for an ErrorList.
 	// Returns 0 if none is set.
 	StatusCode() int
 }
@@ -37,6 +39,10 @@ type ErrorList []*Error
 
 // Validate checks all errors within the list to ensure that they are valid
 func (e ErrorList) Validate(r *http.Request, response bool) *Error {
+	if len(e) == 0 {
+		return ISE("Error list cannot be empty")
+	}
+
 	for _, err := range e {
 		validationErr := err.Validate(r, response)
 		if validationErr != nil {
@@ -58,22 +64,84 @@ func (e ErrorList) Error() string {
 	return msg
 }
 
+// Unwrap returns the errors of the list, for use with errors.Is and errors.As.
+func (e ErrorList) Unwrap() []error {
+	errors := make([]error, 0, len(e))
+	for _, err := range e {
+		errors = append(errors, err)
+	}
+	return errors
+}
+
 /*
-StatusCode (HTTP) of the first error in the list. Defaults to 0 if the list is
-empty or one has not yet been set for the first error.
+StatusCode (HTTP) of the response containing the errors of the list, as computed
+by ErrorStatusPolicy. Defaults to 0 if the list is empty or no status has been set.
 */
 func (e ErrorList) StatusCode() int {
 	if len(e) == 0 {
 		return 0
 	}
 
-	return e[0].Status
+	return ErrorStatusPolicy(e)
+}
+
+// StatusPolicy computes the HTTP Status of a response from the errors it contains.
+type StatusPolicy func(errors ErrorList) int
+
+/*
+ErrorStatusPolicy is the policy used to compute the HTTP Status of error responses.
+It can be replaced to customize the status of responses with multiple errors, for
+instance to keep the status of the first error:
+
+	jsh.ErrorStatusPolicy = jsh.FirstErrorStatus
+*/
+var ErrorStatusPolicy StatusPolicy = AggregateErrorStatus
+
+/*
+AggregateErrorStatus implements the recommendation of the JSON API specification for
+responses with multiple errors: http://jsonapi.org/format/#errors-processing
+
+When all errors share the same status it is used, otherwise the most generally
+applicable status is used: 500 if one of the errors is a server error, 400 otherwise.
+Errors without status are ignored.
+*/
+func AggregateErrorStatus(errors ErrorList) int {
+	status := 0
+	for _, err := range errors {
+		switch {
+		case err.Status == 0 || err.Status == status:
+		case status == 0:
+			status = err.Status
+		case err.Status >= 500 || status >= 500:
+			status = http.StatusInternalServerError
+		default:
+			status = http.StatusBadRequest
+		}
+	}
+	return status
+}
+
+// FirstErrorStatus returns the status of the first error of the list.
+func FirstErrorStatus(errors ErrorList) int {
+	if len(errors) == 0 {
+		return 0
+	}
+	return errors[0].Status
 }
 
-// ErrorSource represents the source of a JSONAPI error, either by a pointer or a query parameter name.
+// ErrorSource represents the source of a JSONAPI error, either by a pointer, a query
+// parameter name or a request header name.
 type ErrorSource struct {
 	Pointer   string `json:"pointer,omitempty"`
 	Parameter string `json:"parameter,omitempty"`
+	Header    string `json:"header,omitempty"`
+}
+
+// ErrorLinks represents the links of a JSONAPI error: a link to further details about
+// this particular occurrence of the problem, and a link identifying the type of problem.
+type ErrorLinks struct {
+	About *Link `json:"about,omitempty"`
+	Type  *Link `json:"type,omitempty"`
 }
 
 /*
@@ -90,12 +158,23 @@ http://jsonapi.org/format/#error-objects
 	jsh.Send(w, r, error)
 */
 type Error struct {
-	Status int          `json:"status,string"`
-	Code   string       `json:"code,omitempty"`
-	Title  string       `json:"title,omitempty"`
-	Detail string       `json:"detail,omitempty"`
-	Source *ErrorSource `json:"source,omitempty"`
-	ISE    string       `json:"-"`
+	ID     string                 `json:"id,omitempty"`
+	Links  *ErrorLinks            `json:"links,omitempty"`
+	Status int                    `json:"status,string"`
+	Code   string                 `json:"code,omitempty"`
+	Title  string                 `json:"title,omitempty"`
+	Detail string                 `json:"detail,omitempty"`
+	Source *ErrorSource           `json:"source,omitempty"`
+	Meta   map[string]interface{} `json:"meta,omitempty"`
+	ISE    string                 `json:"-"`
+	// TitleKey, DetailKey and their arguments identify the title and detail in the
+	// message catalog, they are used by Send to localize the error (see Localize).
+	TitleKey   string        `json:"-"`
+	TitleArgs  []interface{} `json:"-"`
+	DetailKey  string        `json:"-"`
+	DetailArgs []interface{} `json:"-"`
+	// Cause is the original error, kept for logging purposes and never sent
+	Cause error `json:"-"`
 }
 
 /*
@@ -113,9 +192,18 @@ func (e *Error) Error() string {
 		msg += fmt.Sprintf(": %s", e.ISE)
 	}
 
+	if e.Cause != nil && e.Cause.Error() != e.ISE {
+		msg += fmt.Sprintf(" (Cause: %s)", e.Cause)
+	}
+
 	return msg
 }
 
+// Unwrap returns the cause of the error, if any, for use with errors.Is and errors.As.
+func (e *Error) Unwrap() error {
+	return e.Cause
+}
+
 /*
 Validate ensures that the error meets all JSON API criteria.
 */
@@ -128,11 +216,71 @@ func (e *Error) Validate(r *http.Request, response bool) *Error {
 		return ISE(fmt.Sprintf("HTTP Status out of valid range for error %+v\n", e))
 	case e.Status == 422 && (e.Source == nil || e.Source.Pointer == ""):
 		return ISE(fmt.Sprintf("Source Pointer must be set for 422 Status error"))
+	case e.Source != nil && e.Source.Pointer != "" && !strings.HasPrefix(e.Source.Pointer, "/"):
+		return ISE(fmt.Sprintf("Source Pointer must be a JSON Pointer for error %+v\n", e))
+	case e.Links != nil && e.Links.About != nil && e.Links.About.HREF == "":
+		return ISE(fmt.Sprintf("About link must have an href for error %+v\n", e))
+	case e.Links != nil && e.Links.Type != nil && e.Links.Type.HREF == "":
+		return ISE(fmt.Sprintf("Type link must have an href for error %+v\n", e))
 	}
 
 	return nil
 }
 
+/*
+WithID sets the unique identifier of this particular occurrence of the problem.
+The With* methods return the error itself so that they can be chained with the
+convenience constructors:
+
+	err := jsh.InputError("Invalid email", "email").
+		WithID(requestID).
+		WithAbout("https://runbooks.example.com/invalid-email").
+		WithMeta("pattern", emailPattern)
+*/
+func (e *Error) WithID(id string) *Error {
+	e.ID = id
+	return e
+}
+
+// WithAbout sets the link leading to further details about this particular occurrence of the problem.
+func (e *Error) WithAbout(href string) *Error {
+	if e.Links == nil {
+		e.Links = &ErrorLinks{}
+	}
+	e.Links.About = &Link{HREF: href}
+	return e
+}
+
+// WithType sets the link identifying the type of error that this particular error is an instance of.
+func (e *Error) WithType(href string) *Error {
+	if e.Links == nil {
+		e.Links = &ErrorLinks{}
+	}
+	e.Links.Type = &Link{HREF: href}
+	return e
+}
+
+// WithMeta adds a non-standard meta-information member to the error.
+func (e *Error) WithMeta(key string, value interface{}) *Error {
+	if e.Meta == nil {
+		e.Meta = map[string]interface{}{}
+	}
+	e.Meta[key] = value
+	return e
+}
+
+// WithCause sets the original error that caused the error.
+func (e *Error) WithCause(err error) *Error {
+	e.Cause = err
+	return e
+}
+
+// WithHeader sets the name of the request header that caused the error as the error source.
+func (e *Error) WithHeader(header string) *Error {
+	e.Source = &ErrorSource{Header: header}
+	return e
+}
+
 /*
 StatusCode (HTTP) for the error. Defaults to 0.
 */
@@ -141,12 +289,14 @@ func (e *Error) StatusCode() int {
 }
 
 // BadRequestError is a convenience function to return a 400 Bad Request response.
+// The message is set as the title of the error, use NewError(CodeBadRequest) for the
+// title of the registry.
 func BadRequestError(msg string, detail string) *Error {
-	return &Error{
-		Title:  msg,
-		Detail: detail,
-		Status: http.StatusBadRequest,
-	}
+	err := newBuiltinError(CodeBadRequest)
+	err.Title = msg
+	err.TitleKey = ""
+	err.Detail = detail
+	return err
 }
 
 /*
@@ -154,56 +304,78 @@ ParameterError creates a properly formatted HTTP Status 400 error with an approp
 user safe message. The err.Source.Parameter field will be set to the parameter "param".
 */
 func ParameterError(msg string, param string) *Error {
-	return &Error{
-		Title:  "Invalid Query Parameter",
-		Detail: msg,
-		Status: http.StatusBadRequest,
-		Source: &ErrorSource{
-			Parameter: strings.ToLower(param),
-		},
-	}
+	err := newBuiltinError(CodeInvalidQueryParameter)
+	err.Detail = msg
+	err.Source = &ErrorSource{Parameter: strings.ToLower(param)}
+	return err
 }
 
 // ForbiddenError is used whenever an attempt to do a forbidden operation is made.
+// The message is set as the title of the error, use NewError(CodeForbidden) for the
+// title of the registry.
 func ForbiddenError(msg string) *Error {
-	return &Error{
-		Title:  msg,
-		Status: http.StatusForbidden,
-	}
+	err := newBuiltinError(CodeForbidden)
+	err.Title = msg
+	err.TitleKey = ""
+	return err
 }
 
 // NotFound returns a 404 formatted error.
 func NotFound(resourceType string, id string) *Error {
-	return &Error{
-		Title:  "Not Found",
-		Detail: fmt.Sprintf("No resource of type '%s' exists for ID: %s", resourceType, id),
-		Status: http.StatusNotFound,
-	}
+	err := newBuiltinError(CodeNotFound)
+	err.Detail = fmt.Sprintf("No resource of type '%s' exists for ID: %s", resourceType, id)
+	err.DetailKey = MessageNotFoundDetail
+	err.DetailArgs = []interface{}{resourceType, id}
+	return err
 }
 
 // SpecificationError returnss a 406 Not Acceptable.
 // It is used whenever the Client violates the JSON API Spec.
 func SpecificationError(detail string) *Error {
-	return &Error{
-		Title:  "JSON API Specification Error",
-		Detail: detail,
-		Status: http.StatusNotAcceptable,
-	}
+	err := newBuiltinError(CodeSpecificationError)
+	err.Detail = detail
+	return err
+}
+
+// NotAcceptableError returns a 406 Not Acceptable error.
+// It is used whenever the client does not accept any media type the server can respond with.
+func NotAcceptableError(detail string) *Error {
+	err := newBuiltinError(CodeNotAcceptable)
+	err.Detail = detail
+	return err.WithHeader("Accept")
+}
+
+// UnsupportedMediaTypeError returns a 415 Unsupported Media Type error.
+// It is used whenever the client sends a request body with an unsupported Content-Type.
+func UnsupportedMediaTypeError(detail string) *Error {
+	err := newBuiltinError(CodeUnsupportedMediaType)
+	err.Detail = detail
+	return err.WithHeader("Content-Type")
+}
+
+/*
+HeaderError creates a properly formatted HTTP Status 400 error with an appropriate
+user safe message. The err.Source.Header field will be set to the header name.
+*/
+func HeaderError(msg string, header string) *Error {
+	err := newBuiltinError(CodeInvalidHeader)
+	err.Detail = msg
+	return err.WithHeader(header)
 }
 
 // ConflictError returns a 409 Conflict error.
 func ConflictError(resourceType string, id string) *Error {
-	var detail string
+	err := newBuiltinError(CodeConflict)
 	if id == "" {
-		detail = fmt.Sprintf("Resource type '%s' does not match URL's", resourceType)
+		err.Detail = fmt.Sprintf("Resource type '%s' does not match URL's", resourceType)
+		err.DetailKey = MessageConflictTypeDetail
+		err.DetailArgs = []interface{}{resourceType}
 	} else {
-		detail = fmt.Sprintf("ID '%s' does not match URL's", id)
-	}
-	return &Error{
-		Title:  "Resource conflict",
-		Detail: detail,
-		Status: http.StatusConflict,
+		err.Detail = fmt.Sprintf("ID '%s' does not match URL's", id)
+		err.DetailKey = MessageConflictIDDetail
+		err.DetailArgs = []interface{}{id}
 	}
+	return err
 }
 
 // TopLevelError is used whenever the client sends a JSON payload with a missing top-level field.
@@ -214,11 +386,11 @@ func TopLevelError(field string) *Error {
 	// the string `"some value"` in the request document `{"": "some value"}`.
 	// The detail message however eliminates the misunderstanding by specifying
 	// the name of the missing field.
-	err := &Error{
-		Detail: fmt.Sprintf("Missing `%s` at document's top level", strings.ToLower(field)),
-		Status: 422,
-		Source: &ErrorSource{Pointer: "/"},
-	}
+	err := newBuiltinError(CodeMissingTopLevelMember)
+	err.Detail = fmt.Sprintf("Missing `%s` at document's top level", strings.ToLower(field))
+	err.DetailKey = MessageTopLevelDetail
+	err.DetailArgs = []interface{}{strings.ToLower(field)}
+	err.Source = &ErrorSource{Pointer: "/"}
 	return err
 }
 
@@ -228,14 +400,10 @@ user safe message. The parameter "attribute" will format err.Source.Pointer to b
 "/data/attributes/<attribute>".
 */
 func InputError(msg string, attribute string) *Error {
-	return &Error{
-		Title:  "Invalid Attribute",
-		Detail: msg,
-		Status: 422,
-		Source: &ErrorSource{
-			Pointer: AttributePointer(attribute),
-		},
-	}
+	err := newBuiltinError(CodeInvalidAttribute)
+	err.Detail = msg
+	err.Source = &ErrorSource{Pointer: AttributePointer(attribute)}
+	return err
 }
 
 /*
@@ -244,14 +412,25 @@ user safe message. The parameter "relationship" will format err.Source.Pointer t
 "/data/relationship/<attribute>".
 */
 func RelationshipError(msg string, relationship string) *Error {
-	return &Error{
-		Title:  "Invalid Relationship",
-		Detail: msg,
-		Status: 422,
-		Source: &ErrorSource{
-			Pointer: RelationshipPointer(relationship),
-		},
-	}
+	err := newBuiltinError(CodeInvalidRelationship)
+	err.Detail = msg
+	err.Source = &ErrorSource{Pointer: RelationshipPointer(relationship)}
+	return err
+}
+
+/*
+UnresolvedLinkageError creates a properly formatted HTTP Status 422 error for a resource
+linkage that matches no resource of a compound document. The pointer locates the
+linkage in the document, and the meta contains its type and id.
+*/
+func UnresolvedLinkageError(linkage *IDObject, pointer string) *Error {
+	key := linkage.key()
+	err := newBuiltinError(CodeUnresolvedLinkage)
+	err.Detail = fmt.Sprintf("Resource %s is not included in the document", key)
+	err.DetailKey = MessageUnresolvedLinkageDetail
+	err.DetailArgs = []interface{}{key.String()}
+	err.Source = &ErrorSource{Pointer: pointer}
+	return err.WithMeta("type", linkage.Type).WithMeta("id", linkage.ID)
 }
 
 /*
@@ -260,21 +439,19 @@ response. The message you pass in is set to the ErrorObject.ISE attribute so you
 can gracefully log ISE's internally before sending them.
 */
 func ISE(internalMessage string) *Error {
-	return &Error{
-		Title:  DefaultErrorTitle,
-		Detail: DefaultErrorDetail,
-		Status: http.StatusInternalServerError,
-		ISE:    internalMessage,
-	}
+	err := newBuiltinError(CodeInternalServerError)
+	err.Title = DefaultErrorTitle
+	err.Detail = DefaultErrorDetail
+	err.DetailKey = MessageInternalServerDetail
+	err.ISE = internalMessage
+	return err
 }
 
 // NotImplemented is a convenience function similar to ISE except if generates a 501 response.
 func NotImplemented(internalMessage string) *Error {
-	return &Error{
-		Title:  "Not implemented",
-		Status: http.StatusNotImplemented,
-		ISE:    internalMessage,
-	}
+	err := newBuiltinError(CodeNotImplemented)
+	err.ISE = internalMessage
+	return err
 }
 
 // AttributePointer returns a JSON pointer to the given attribute in a JSON API document.
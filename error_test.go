@@ -1,6 +1,7 @@
 package jsh
 
 import (
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"strconv"
@@ -50,6 +51,66 @@ func TestError(t *testing.T) {
 				err := testErrorObject.Validate(request, true)
 				So(err, ShouldNotBeNil)
 			})
+
+			Convey("should fail for a source pointer that is not a JSON pointer", func() {
+				testErrorObject.Source = &ErrorSource{Pointer: "data"}
+				err := testErrorObject.Validate(request, true)
+				So(err, ShouldNotBeNil)
+			})
+
+			Convey("should fail for links without href", func() {
+				testErrorObject.Links = &ErrorLinks{About: &Link{}}
+				err := testErrorObject.Validate(request, true)
+				So(err, ShouldNotBeNil)
+			})
+		})
+
+		Convey("->StatusCode()", func() {
+
+			Convey("should use the shared status of all errors", func() {
+				errors := ErrorList{InputError("Invalid", "a"), InputError("Invalid", "b")}
+				So(errors.StatusCode(), ShouldEqual, 422)
+			})
+
+			Convey("should use the most general status class otherwise", func() {
+				errors := ErrorList{InputError("Invalid", "a"), ConflictError("tests", "1")}
+				So(errors.StatusCode(), ShouldEqual, http.StatusBadRequest)
+
+				errors = append(errors, NotImplemented("Later"))
+				So(errors.StatusCode(), ShouldEqual, http.StatusInternalServerError)
+			})
+
+			Convey("should use the configured policy", func() {
+				ErrorStatusPolicy = FirstErrorStatus
+				Reset(func() {
+					ErrorStatusPolicy = AggregateErrorStatus
+				})
+
+				errors := ErrorList{InputError("Invalid", "a"), ConflictError("tests", "1")}
+				So(errors.StatusCode(), ShouldEqual, 422)
+			})
+
+			Convey("should default to 0 for an empty list", func() {
+				So(ErrorList{}.StatusCode(), ShouldEqual, 0)
+			})
+		})
+
+		Convey("->With*()", func() {
+			err := InputError("Invalid email", "email").
+				WithID("42").
+				WithAbout("http://example.com/runbooks/email").
+				WithType("http://example.com/errors/invalid-attribute").
+				WithMeta("pattern", ".+@.+")
+			So(err.Validate(request, true), ShouldBeNil)
+
+			raw, jsonErr := json.Marshal(err)
+			So(jsonErr, ShouldBeNil)
+			So(string(raw), ShouldEqual, `{"id":"42","links":{"about":"http://example.com/runbooks/email",`+
+				`"type":"http://example.com/errors/invalid-attribute"},"status":"422","code":"invalid_attribute","title":"Invalid Attribute",`+
+				`"detail":"Invalid email","source":{"pointer":"/data/attributes/email"},"meta":{"pattern":".+@.+"}}`)
+
+			err = HeaderError("Invalid token", "Authorization").WithHeader("X-Token")
+			So(err.Source, ShouldResemble, &ErrorSource{Header: "X-Token"})
 		})
 
 		Convey("->Send()", func() {
@@ -71,6 +132,18 @@ func TestError(t *testing.T) {
 				So(writer.HeaderMap.Get("Content-Type"), ShouldEqual, ContentType)
 			})
 
+			Convey("should send the aggregate status of an ErrorList", func() {
+				err := Send(writer, request, ErrorList{testError, NotFound("tests", "1")})
+				So(err, ShouldBeNil)
+				So(writer.Code, ShouldEqual, http.StatusBadRequest)
+			})
+
+			Convey("should send an ISE for an empty ErrorList", func() {
+				err := Send(writer, request, ErrorList{})
+				So(err, ShouldNotBeNil)
+				So(writer.Code, ShouldEqual, http.StatusInternalServerError)
+			})
+
 			Convey("should work for an ErrorList", func() {
 
 				errorList := ErrorList{testError}
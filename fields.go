@@ -0,0 +1,174 @@
+package jsh
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+const paramFields = "fields"
+
+/*
+Fieldsets holds the sparse fieldsets requested by a client, by resource type:
+http://jsonapi.org/format/#fetching-sparse-fieldsets
+
+A resource type without fieldset is sent with all of its fields, while a resource
+type with an empty fieldset is sent without any attribute or relationship.
+*/
+type Fieldsets map[string][]string
+
+/*
+ParseFieldsets parses the "fields[TYPE]" query parameters of the request.
+
+	GET /articles?include=author&fields[articles]=title,body&fields[people]=name
+
+The allowed fieldsets declare which fields of which resource types can be requested.
+A ParameterError is returned if a requested type or field is not allowed. If allowed
+is nil, any type and field is accepted.
+
+	fields, err := jsh.ParseFieldsets(r, jsh.Fieldsets{
+		"articles": {"title", "body", "author"},
+		"people":   {"name"},
+	})
+*/
+func ParseFieldsets(r *http.Request, allowed Fieldsets) (Fieldsets, *Error) {
+	params, err := queryFamily(query(r), paramFields)
+	if err != nil {
+		return nil, err
+	}
+
+	fieldsets := Fieldsets{}
+	for _, param := range params {
+		if len(param.Members) != 1 {
+			return nil, ParameterError(fmt.Sprintf("Malformed query parameter '%s'", param.Key), param.Key)
+		}
+		resourceType := param.Members[0]
+		fields, ok := splitList(param.Value)
+		if !ok {
+			return nil, ParameterError("Empty field name in fieldset", param.Key)
+		}
+		if allowed != nil {
+			allowedFields, ok := allowed[resourceType]
+			if !ok {
+				return nil, ParameterError(fmt.Sprintf("Unknown resource type '%s'", resourceType), param.Key)
+			}
+			for _, field := range fields {
+				if !containsString(allowedFields, field) {
+					return nil, ParameterError(fmt.Sprintf("Unknown field '%s'", field), param.Key)
+				}
+			}
+		}
+		fieldsets[resourceType] = fields
+	}
+	return fieldsets, nil
+}
+
+// Has returns true if the given field of the resource type should be sent to the client.
+func (f Fieldsets) Has(resourceType, field string) bool {
+	fields, ok := f[resourceType]
+	if !ok {
+		return true
+	}
+	return containsString(fields, field)
+}
+
+/*
+Prune removes the attributes and relationships of the given object that are not part
+of its type's fieldset. It is a no-op if the object type has no fieldset.
+*/
+func (f Fieldsets) Prune(object *Object) *Error {
+	fields, ok := f[object.Type]
+	if !ok {
+		return nil
+	}
+	return object.Prune(fields)
+}
+
+// pruned returns a copy of the object pruned to its type's fieldset, so that the object
+// of the caller keeps all of its fields. The object is returned as is if its type has
+// no fieldset.
+func (f Fieldsets) pruned(object *Object) (*Object, *Error) {
+	fields, ok := f[object.Type]
+	if !ok {
+		return object, nil
+	}
+	clone := *object
+	if object.Relationships != nil {
+		clone.Relationships = make(map[string]*Relationship, len(object.Relationships))
+		for name, relationship := range object.Relationships {
+			clone.Relationships[name] = relationship
+		}
+	}
+	if err := clone.Prune(fields); err != nil {
+		return nil, err
+	}
+	return &clone, nil
+}
+
+// Prune removes every attribute and relationship of the object that is not in fields.
+func (o *Object) Prune(fields []string) *Error {
+	for name := range o.Relationships {
+		if !containsString(fields, name) {
+			delete(o.Relationships, name)
+		}
+	}
+
+	if len(o.Attributes) == 0 {
+		return nil
+	}
+	attrs := map[string]json.RawMessage{}
+	if err := json.Unmarshal(o.Attributes, &attrs); err != nil {
+		return ISE(fmt.Sprintf("Unable to decode attributes of '%s' for sparse fieldset: %s", o.Type, err))
+	}
+	for name := range attrs {
+		if !containsString(fields, name) {
+			delete(attrs, name)
+		}
+	}
+	raw, err := json.Marshal(attrs)
+	if err != nil {
+		return ISE(fmt.Sprintf("Unable to encode attributes of '%s' for sparse fieldset: %s", o.Type, err))
+	}
+	o.Attributes = raw
+	return nil
+}
+
+/*
+Prune applies the given sparse fieldsets to the primary data and the included
+resources of the document. The pruned resources are copies, the objects added to
+the document are left untouched so that they can be reused, e.g. from a cache.
+
+Send calls it automatically with the fieldsets of the request. Build has no request
+and does not prune, call Prune on the built document to send it by other means.
+*/
+func (d *Document) Prune(fields Fieldsets) *Error {
+	if len(fields) == 0 || d.Mode == ErrorMode {
+		return nil
+	}
+	data, err := fields.prunedList(d.Data)
+	if err != nil {
+		return err
+	}
+	included, err := fields.prunedList(d.Included)
+	if err != nil {
+		return err
+	}
+	d.Data = data
+	d.Included = included
+	return nil
+}
+
+// prunedList returns a new list of the objects pruned to their fieldsets.
+func (f Fieldsets) prunedList(objects []*Object) ([]*Object, *Error) {
+	if objects == nil {
+		return nil, nil
+	}
+	pruned := make([]*Object, len(objects))
+	for i, object := range objects {
+		var err *Error
+		if pruned[i], err = f.pruned(object); err != nil {
+			return nil, err
+		}
+	}
+	return pruned, nil
+}
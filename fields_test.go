@@ -0,0 +1,158 @@
+package jsh
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestFieldsets(t *testing.T) {
+
+	Convey("Fieldsets Tests", t, func() {
+
+		allowed := Fieldsets{
+			"articles": {"title", "body", "author"},
+			"people":   {"name"},
+		}
+
+		Convey("->ParseFieldsets()", func() {
+
+			Convey("should parse fieldsets by resource type", func() {
+				req, err := http.NewRequest("GET", "/articles?fields[articles]=title,author&fields[people]=name", nil)
+				So(err, ShouldBeNil)
+
+				fields, parseErr := ParseFieldsets(req, allowed)
+				So(parseErr, ShouldBeNil)
+				So(fields, ShouldResemble, Fieldsets{
+					"articles": {"title", "author"},
+					"people":   {"name"},
+				})
+			})
+
+			Convey("should accept an empty fieldset", func() {
+				req, err := http.NewRequest("GET", "/articles?fields[articles]=", nil)
+				So(err, ShouldBeNil)
+
+				fields, parseErr := ParseFieldsets(req, allowed)
+				So(parseErr, ShouldBeNil)
+				So(fields["articles"], ShouldBeEmpty)
+				So(fields.Has("articles", "title"), ShouldBeFalse)
+				So(fields.Has("people", "name"), ShouldBeTrue)
+			})
+
+			Convey("should accept anything without allowed fieldsets", func() {
+				req, err := http.NewRequest("GET", "/articles?fields[foos]=bar", nil)
+				So(err, ShouldBeNil)
+
+				fields, parseErr := ParseFieldsets(req, nil)
+				So(parseErr, ShouldBeNil)
+				So(fields["foos"], ShouldResemble, []string{"bar"})
+			})
+
+			Convey("should reject malformed parameters", func() {
+				for _, q := range []string{"fields=title", "fields[articles=title", "fields[]=title", "fields[a][b]=title", "fields[articles]=title,,body"} {
+					req, err := http.NewRequest("GET", "/articles?"+q, nil)
+					So(err, ShouldBeNil)
+
+					_, parseErr := ParseFieldsets(req, allowed)
+					So(parseErr, ShouldNotBeNil)
+					So(parseErr.Status, ShouldEqual, http.StatusBadRequest)
+				}
+			})
+
+			Convey("should reject unknown types and fields", func() {
+				req, err := http.NewRequest("GET", "/articles?fields[comments]=body", nil)
+				So(err, ShouldBeNil)
+
+				_, parseErr := ParseFieldsets(req, allowed)
+				So(parseErr, ShouldNotBeNil)
+				So(parseErr.Source.Parameter, ShouldEqual, "fields[comments]")
+
+				req, err = http.NewRequest("GET", "/articles?fields[people]=age", nil)
+				So(err, ShouldBeNil)
+
+				_, parseErr = ParseFieldsets(req, allowed)
+				So(parseErr, ShouldNotBeNil)
+				So(parseErr.Source.Parameter, ShouldEqual, "fields[people]")
+			})
+		})
+
+		Convey("->Prune()", func() {
+			object, err := NewObject("1", "articles", map[string]string{"title": "JSON API", "body": "..."})
+			So(err, ShouldBeNil)
+			object.AddRelationshipOne("author", NewIDObject("people", "9"))
+			object.AddRelationshipMany("comments", IDList{NewIDObject("comments", "5")})
+
+			Convey("should remove attributes and relationships", func() {
+				err := object.Prune([]string{"title", "comments"})
+				So(err, ShouldBeNil)
+				So(string(object.Attributes), ShouldEqual, `{"title":"JSON API"}`)
+				So(object.Relationships, ShouldContainKey, "comments")
+				So(object.Relationships, ShouldNotContainKey, "author")
+			})
+
+			Convey("should prune primary data and included resources of a document", func() {
+				person, err := NewObject("9", "people", map[string]string{"name": "Dan", "email": "dan@example.com"})
+				So(err, ShouldBeNil)
+
+				doc := Build(object)
+				doc.Included = append(doc.Included, person)
+
+				pruneErr := doc.Prune(Fieldsets{"people": {"name"}})
+				So(pruneErr, ShouldBeNil)
+				So(string(doc.Included[0].Attributes), ShouldEqual, `{"name":"Dan"}`)
+				So(doc.Data[0], ShouldEqual, object)
+				So(object.Relationships, ShouldContainKey, "author")
+			})
+
+			Convey("should leave the objects of the document untouched", func() {
+				doc := Build(object)
+
+				pruneErr := doc.Prune(Fieldsets{"articles": {"title"}})
+				So(pruneErr, ShouldBeNil)
+				So(string(doc.Data[0].Attributes), ShouldEqual, `{"title":"JSON API"}`)
+				So(doc.Data[0].Relationships, ShouldBeEmpty)
+				So(string(object.Attributes), ShouldEqual, `{"body":"...","title":"JSON API"}`)
+				So(object.Relationships, ShouldContainKey, "author")
+				So(object.Relationships, ShouldContainKey, "comments")
+			})
+		})
+
+		Convey("->Send()", func() {
+			object, err := NewObject("1", "articles", map[string]string{"title": "JSON API", "body": "..."})
+			So(err, ShouldBeNil)
+
+			Convey("should apply the request fieldsets", func() {
+				req, err := http.NewRequest("GET", "/articles/1?fields[articles]=body", nil)
+				So(err, ShouldBeNil)
+				writer := httptest.NewRecorder()
+
+				sendErr := Send(writer, req, object)
+				So(sendErr, ShouldBeNil)
+				So(writer.Code, ShouldEqual, http.StatusOK)
+
+				doc := struct {
+					Data struct {
+						Attributes map[string]string `json:"attributes"`
+					} `json:"data"`
+				}{}
+				So(json.Unmarshal(writer.Body.Bytes(), &doc), ShouldBeNil)
+				So(doc.Data.Attributes, ShouldResemble, map[string]string{"body": "..."})
+				So(string(object.Attributes), ShouldEqual, `{"body":"...","title":"JSON API"}`)
+			})
+
+			Convey("should respond with an error for malformed fieldsets", func() {
+				req, err := http.NewRequest("GET", "/articles/1?fields=body", nil)
+				So(err, ShouldBeNil)
+				writer := httptest.NewRecorder()
+
+				sendErr := Send(writer, req, object)
+				So(sendErr, ShouldNotBeNil)
+				So(writer.Code, ShouldEqual, http.StatusBadRequest)
+			})
+		})
+	})
+}
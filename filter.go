@@ -0,0 +1,352 @@
+package jsh
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+)
+
+const paramFilter = "filter"
+
+// FilterOperator is the comparison operator of a filter condition.
+type FilterOperator string
+
+const (
+	// FilterEqual matches values equal to the condition value
+	FilterEqual FilterOperator = "eq"
+	// FilterNotEqual matches values different from the condition value
+	FilterNotEqual FilterOperator = "ne"
+	// FilterGreater matches values strictly greater than the condition value
+	FilterGreater FilterOperator = "gt"
+	// FilterGreaterOrEqual matches values greater or equal to the condition value
+	FilterGreaterOrEqual FilterOperator = "gte"
+	// FilterLess matches values strictly less than the condition value
+	FilterLess FilterOperator = "lt"
+	// FilterLessOrEqual matches values less or equal to the condition value
+	FilterLessOrEqual FilterOperator = "lte"
+	// FilterIn matches values equal to one of the condition values
+	FilterIn FilterOperator = "in"
+	// FilterNotIn matches values different from all of the condition values
+	FilterNotIn FilterOperator = "nin"
+)
+
+// FilterLogic is the logical operator of a filter expression.
+type FilterLogic string
+
+const (
+	// FilterAnd matches when every operand of the expression matches
+	FilterAnd FilterLogic = "and"
+	// FilterOr matches when at least one operand of the expression matches
+	FilterOr FilterLogic = "or"
+	// FilterNot matches when the conjunction of the operands of the expression does not match
+	FilterNot FilterLogic = "not"
+)
+
+// filterGroups are the logical operators of grouped filter expressions.
+var filterGroups = []string{string(FilterAnd), string(FilterOr), string(FilterNot)}
+
+// filterOperators lists every supported operator, and whether it accepts a list of values.
+var filterOperators = map[FilterOperator]bool{
+	FilterEqual:          false,
+	FilterNotEqual:       false,
+	FilterGreater:        false,
+	FilterGreaterOrEqual: false,
+	FilterLess:           false,
+	FilterLessOrEqual:    false,
+	FilterIn:             true,
+	FilterNotIn:          true,
+}
+
+// FilterRules declares the operators allowed for each filterable field of a resource type.
+type FilterRules map[string][]FilterOperator
+
+// FilterCondition is a leaf of a filter expression: a comparison of a field with one
+// or more values.
+type FilterCondition struct {
+	Field    string
+	Operator FilterOperator
+	Values   []string
+}
+
+// Value returns the first value of the condition, which is the only one for operators
+// other than FilterIn and FilterNotIn.
+func (c *FilterCondition) Value() string {
+	if len(c.Values) == 0 {
+		return ""
+	}
+	return c.Values[0]
+}
+
+/*
+Filter is the conjunction of every condition requested through the filter[...] query
+parameters, sorted by query parameter. Grouped expressions such as disjunctions are
+returned by ParseFilterExpression only.
+*/
+type Filter []*FilterCondition
+
+/*
+FilterExpression is a node of a filter expression tree: the logical combination of
+its conditions and of its child expressions. The root of the tree returned by
+ParseFilterExpression is always a FilterAnd expression.
+*/
+type FilterExpression struct {
+	Logic      FilterLogic
+	Conditions Filter
+	Children   []*FilterExpression
+}
+
+/*
+ParseFilter parses the filter[...] query parameters of the request and validates them
+against the rules of the resource type. Both the simple equality and the operator
+syntax are supported:
+
+	filter[status]=open     -> status eq open
+	filter[id]=1,2,3        -> id in 1,2,3
+	filter[age][gte]=18     -> age gte 18
+	filter[id][nin]=4,5     -> id nin 4,5
+
+	filter, err := jsh.ParseFilter(r, jsh.FilterRules{
+		"status": {jsh.FilterEqual, jsh.FilterIn},
+		"age":    {jsh.FilterGreaterOrEqual, jsh.FilterLessOrEqual},
+	})
+
+A ParameterError is returned for malformed filters, unknown fields or operators that
+are not allowed for the field. Grouped expressions such as "filter[or][0][status]=open"
+or "filter[not][status]=open" are rejected as well, so that a client never receives
+a result that silently ignores part of its filter. Use ParseFilterExpression to
+support them.
+*/
+func ParseFilter(r *http.Request, rules FilterRules) (Filter, *Error) {
+	params, err := queryFamily(query(r), paramFilter)
+	if err != nil {
+		return nil, err
+	}
+	for _, param := range params {
+		if isFilterGroup(param.Members[0], rules) {
+			return nil, ParameterError(fmt.Sprintf("Grouped filter expressions such as '%s' are not supported", param.Members[0]), param.Key)
+		}
+	}
+
+	expression, err := parseFilterExpression(FilterAnd, filterOperands(params), rules)
+	if err != nil {
+		return nil, err
+	}
+	return expression.Conditions, nil
+}
+
+/*
+ParseFilterExpression parses the filter[...] query parameters of the request into an
+expression tree and validates its conditions against the rules of the resource type.
+On top of the syntax supported by ParseFilter, conditions can be grouped with the
+"and", "or" and "not" logical operators. The operands of "and" and "or" are indexed,
+every condition sharing an index belongs to the same operand:
+
+	filter[or][0][status]=open&filter[or][1][age][gte]=18
+		-> status eq open OR age gte 18
+	filter[or][0][status]=open&filter[or][0][age][lt]=65&filter[or][1][id]=1
+		-> (status eq open AND age lt 65) OR id eq 1
+	filter[not][status]=closed&filter[age][gte]=18
+		-> NOT (status eq closed) AND age gte 18
+
+Groups can be nested, e.g. "filter[not][or][0][status]=open". A field declared in the
+rules with the name of a logical operator is parsed as a field.
+*/
+func ParseFilterExpression(r *http.Request, rules FilterRules) (*FilterExpression, *Error) {
+	params, err := queryFamily(query(r), paramFilter)
+	if err != nil {
+		return nil, err
+	}
+	return parseFilterExpression(FilterAnd, filterOperands(params), rules)
+}
+
+// Get returns the conditions on the given field.
+func (f Filter) Get(field string) []*FilterCondition {
+	var conditions []*FilterCondition
+	for _, condition := range f {
+		if condition.Field == field {
+			conditions = append(conditions, condition)
+		}
+	}
+	return conditions
+}
+
+// Has returns true if the filter has a condition on the field with the given operator.
+func (f Filter) Has(field string, operator FilterOperator) bool {
+	for _, condition := range f.Get(field) {
+		if condition.Operator == operator {
+			return true
+		}
+	}
+	return false
+}
+
+// filterOperand is a filter query parameter with the members that remain to be parsed
+// at the current level of the expression tree.
+type filterOperand struct {
+	param   *queryParam
+	members []string
+}
+
+// filterOperands returns the operands of the root of the expression tree.
+func filterOperands(params []*queryParam) []*filterOperand {
+	operands := make([]*filterOperand, len(params))
+	for i, param := range params {
+		operands[i] = &filterOperand{param: param, members: param.Members}
+	}
+	return operands
+}
+
+// isFilterGroup returns true if the member is a logical operator rather than a field.
+func isFilterGroup(member string, rules FilterRules) bool {
+	_, field := rules[member]
+	return !field && containsString(filterGroups, member)
+}
+
+// parseFilterExpression parses the operands of an expression with the given logic.
+// The operands of "and" and "or" groups are combined into a conjunction for each index.
+func parseFilterExpression(logic FilterLogic, operands []*filterOperand, rules FilterRules) (*FilterExpression, *Error) {
+	expression := &FilterExpression{Logic: logic, Conditions: Filter{}}
+	var groups []FilterLogic
+	grouped := map[FilterLogic][]*filterOperand{}
+
+	for _, operand := range operands {
+		if isFilterGroup(operand.members[0], rules) {
+			group := FilterLogic(operand.members[0])
+			if len(operand.members) < 2 {
+				return nil, ParameterError(fmt.Sprintf("Missing filter expression in '%s'", operand.param.Key), operand.param.Key)
+			}
+			if _, ok := grouped[group]; !ok {
+				groups = append(groups, group)
+			}
+			grouped[group] = append(grouped[group], &filterOperand{param: operand.param, members: operand.members[1:]})
+			continue
+		}
+
+		condition, err := parseFilterCondition(operand.members, operand.param)
+		if err != nil {
+			return nil, err
+		}
+		if err := validateFilterCondition(condition, rules, operand.param); err != nil {
+			return nil, err
+		}
+		if expression.Conditions.Has(condition.Field, condition.Operator) {
+			return nil, ParameterError(fmt.Sprintf(
+				"Operator '%s' is set more than once for '%s'",
+				condition.Operator,
+				condition.Field,
+			), operand.param.Key)
+		}
+		expression.Conditions = append(expression.Conditions, condition)
+	}
+
+	for _, group := range groups {
+		var child *FilterExpression
+		var err *Error
+		if group == FilterNot {
+			child, err = parseFilterExpression(FilterNot, grouped[group], rules)
+		} else {
+			child, err = parseFilterGroup(group, grouped[group], rules)
+		}
+		if err != nil {
+			return nil, err
+		}
+		expression.Children = append(expression.Children, child)
+	}
+	return expression, nil
+}
+
+// parseFilterGroup parses the indexed operands of an "and" or "or" group. An operand made
+// of a single condition is added to the conditions of the group, other operands are
+// added as conjunctions to its children.
+func parseFilterGroup(logic FilterLogic, operands []*filterOperand, rules FilterRules) (*FilterExpression, *Error) {
+	var indexes []int
+	indexed := map[int][]*filterOperand{}
+	for _, operand := range operands {
+		index, err := strconv.Atoi(operand.members[0])
+		if err != nil || index < 0 {
+			return nil, ParameterError(fmt.Sprintf("Invalid filter operand index in '%s'", operand.param.Key), operand.param.Key)
+		}
+		if len(operand.members) < 2 {
+			return nil, ParameterError(fmt.Sprintf("Missing filter expression in '%s'", operand.param.Key), operand.param.Key)
+		}
+		if _, ok := indexed[index]; !ok {
+			indexes = append(indexes, index)
+		}
+		indexed[index] = append(indexed[index], &filterOperand{param: operand.param, members: operand.members[1:]})
+	}
+	sort.Ints(indexes)
+
+	group := &FilterExpression{Logic: logic, Conditions: Filter{}}
+	for _, index := range indexes {
+		child, err := parseFilterExpression(FilterAnd, indexed[index], rules)
+		if err != nil {
+			return nil, err
+		}
+		if len(child.Conditions) == 1 && len(child.Children) == 0 {
+			group.Conditions = append(group.Conditions, child.Conditions[0])
+			continue
+		}
+		group.Children = append(group.Children, child)
+	}
+	return group, nil
+}
+
+// validateFilterCondition checks the condition against the rules of the resource type.
+func validateFilterCondition(condition *FilterCondition, rules FilterRules, param *queryParam) *Error {
+	allowed, ok := rules[condition.Field]
+	if !ok {
+		return ParameterError(fmt.Sprintf("Filtering by '%s' is not supported", condition.Field), param.Key)
+	}
+	if !containsOperator(allowed, condition.Operator) {
+		return ParameterError(fmt.Sprintf(
+			"Operator '%s' is not supported for '%s'",
+			condition.Operator,
+			condition.Field,
+		), param.Key)
+	}
+	return nil
+}
+
+// parseFilterCondition parses the remaining members of a filter query parameter into a condition.
+func parseFilterCondition(members []string, param *queryParam) (*FilterCondition, *Error) {
+	if len(members) > 2 {
+		return nil, ParameterError(fmt.Sprintf("Malformed filter '%s'", param.Key), param.Key)
+	}
+	values, ok := splitList(param.Value)
+	if !ok || len(values) == 0 {
+		return nil, ParameterError("Missing filter value", param.Key)
+	}
+
+	condition := &FilterCondition{
+		Field:  members[0],
+		Values: values,
+	}
+	switch {
+	case len(members) == 2:
+		condition.Operator = FilterOperator(members[1])
+	case len(values) > 1:
+		condition.Operator = FilterIn
+	default:
+		condition.Operator = FilterEqual
+	}
+
+	list, ok := filterOperators[condition.Operator]
+	if !ok {
+		return nil, ParameterError(fmt.Sprintf("Unknown filter operator '%s'", condition.Operator), param.Key)
+	}
+	if !list && len(values) > 1 {
+		return nil, ParameterError(fmt.Sprintf("Operator '%s' accepts a single value", condition.Operator), param.Key)
+	}
+	return condition, nil
+}
+
+// containsOperator returns true if the list contains the given operator.
+func containsOperator(list []FilterOperator, operator FilterOperator) bool {
+	for _, elem := range list {
+		if elem == operator {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,189 @@
+package jsh
+
+import (
+	"net/http"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestFilter(t *testing.T) {
+
+	Convey("Filter Tests", t, func() {
+
+		rules := FilterRules{
+			"status": {FilterEqual, FilterIn},
+			"id":     {FilterIn, FilterNotIn},
+			"age":    {FilterGreaterOrEqual, FilterLess},
+		}
+
+		parse := func(rawQuery string) (Filter, *Error) {
+			req, err := http.NewRequest("GET", "/people?"+rawQuery, nil)
+			So(err, ShouldBeNil)
+			return ParseFilter(req, rules)
+		}
+
+		parseExpression := func(rawQuery string) (*FilterExpression, *Error) {
+			req, err := http.NewRequest("GET", "/people?"+rawQuery, nil)
+			So(err, ShouldBeNil)
+			return ParseFilterExpression(req, rules)
+		}
+
+		Convey("->ParseFilter()", func() {
+
+			Convey("should parse simple equality", func() {
+				filter, err := parse("filter[status]=open")
+				So(err, ShouldBeNil)
+				So(filter, ShouldResemble, Filter{
+					{Field: "status", Operator: FilterEqual, Values: []string{"open"}},
+				})
+				So(filter[0].Value(), ShouldEqual, "open")
+			})
+
+			Convey("should parse a list of values as inclusion", func() {
+				filter, err := parse("filter[id]=1,2,3")
+				So(err, ShouldBeNil)
+				So(filter, ShouldResemble, Filter{
+					{Field: "id", Operator: FilterIn, Values: []string{"1", "2", "3"}},
+				})
+			})
+
+			Convey("should parse the operator syntax", func() {
+				filter, err := parse("filter[age][gte]=18&filter[age][lt]=65&filter[id][nin]=4")
+				So(err, ShouldBeNil)
+				So(filter, ShouldHaveLength, 3)
+				So(filter.Get("age"), ShouldHaveLength, 2)
+				So(filter.Has("age", FilterGreaterOrEqual), ShouldBeTrue)
+				So(filter.Has("age", FilterLess), ShouldBeTrue)
+				So(filter.Has("id", FilterNotIn), ShouldBeTrue)
+				So(filter.Has("status", FilterEqual), ShouldBeFalse)
+			})
+
+			Convey("should return an empty filter without filter parameters", func() {
+				filter, err := parse("sort=age")
+				So(err, ShouldBeNil)
+				So(filter, ShouldBeEmpty)
+			})
+
+			Convey("should reject invalid filters", func() {
+				invalid := map[string]string{
+					"filter=open":                           "filter",
+					"filter[name]=bob":                      "filter[name]",
+					"filter[age]=18":                        "filter[age]",
+					"filter[age][gt]=18":                    "filter[age][gt]",
+					"filter[age][foo]=18":                   "filter[age][foo]",
+					"filter[age][gte]=18,20":                "filter[age][gte]",
+					"filter[age][gte][x]=18":                "filter[age][gte][x]",
+					"filter[status]=":                       "filter[status]",
+					"filter[status]=a&filter[status][eq]=b": "filter[status][eq]",
+				}
+				for q, param := range invalid {
+					_, err := parse(q)
+					So(err, ShouldNotBeNil)
+					So(err.Status, ShouldEqual, http.StatusBadRequest)
+					So(err.Source.Parameter, ShouldEqual, param)
+				}
+			})
+
+			Convey("should reject grouped expressions", func() {
+				for _, q := range []string{"filter[or][0][status]=open", "filter[not][status]=open", "filter[and][status]=open"} {
+					_, err := parse(q)
+					So(err, ShouldNotBeNil)
+					So(err.Status, ShouldEqual, http.StatusBadRequest)
+					So(err.Detail, ShouldContainSubstring, "not supported")
+				}
+			})
+
+			Convey("should report the parameter in lowercase", func() {
+				_, err := parse("filter[Status][GTE]=1")
+				So(err, ShouldNotBeNil)
+				So(err.Source.Parameter, ShouldEqual, "filter[status][gte]")
+			})
+		})
+
+		Convey("->ParseFilterExpression()", func() {
+
+			Convey("should parse a conjunction of conditions", func() {
+				expression, err := parseExpression("filter[status]=open&filter[age][gte]=18")
+				So(err, ShouldBeNil)
+				So(expression, ShouldResemble, &FilterExpression{
+					Logic: FilterAnd,
+					Conditions: Filter{
+						{Field: "age", Operator: FilterGreaterOrEqual, Values: []string{"18"}},
+						{Field: "status", Operator: FilterEqual, Values: []string{"open"}},
+					},
+				})
+			})
+
+			Convey("should parse disjunctions of indexed operands", func() {
+				expression, err := parseExpression("filter[or][1][age][gte]=18&filter[or][0][status]=open&filter[or][0][id]=1,2")
+				So(err, ShouldBeNil)
+				So(expression, ShouldResemble, &FilterExpression{
+					Logic:      FilterAnd,
+					Conditions: Filter{},
+					Children: []*FilterExpression{{
+						Logic: FilterOr,
+						Conditions: Filter{
+							{Field: "age", Operator: FilterGreaterOrEqual, Values: []string{"18"}},
+						},
+						Children: []*FilterExpression{{
+							Logic: FilterAnd,
+							Conditions: Filter{
+								{Field: "id", Operator: FilterIn, Values: []string{"1", "2"}},
+								{Field: "status", Operator: FilterEqual, Values: []string{"open"}},
+							},
+						}},
+					}},
+				})
+			})
+
+			Convey("should parse nested negations", func() {
+				expression, err := parseExpression("filter[not][or][0][status]=open&filter[not][or][1][status]=closed&filter[age][lt]=65")
+				So(err, ShouldBeNil)
+				So(expression.Conditions, ShouldResemble, Filter{
+					{Field: "age", Operator: FilterLess, Values: []string{"65"}},
+				})
+				So(expression.Children, ShouldHaveLength, 1)
+
+				not := expression.Children[0]
+				So(not.Logic, ShouldEqual, FilterNot)
+				So(not.Conditions, ShouldBeEmpty)
+				So(not.Children, ShouldResemble, []*FilterExpression{{
+					Logic: FilterOr,
+					Conditions: Filter{
+						{Field: "status", Operator: FilterEqual, Values: []string{"open"}},
+						{Field: "status", Operator: FilterEqual, Values: []string{"closed"}},
+					},
+				}})
+			})
+
+			Convey("should parse declared fields named after a logical operator", func() {
+				rules["not"] = []FilterOperator{FilterEqual}
+				expression, err := parseExpression("filter[not]=1")
+				So(err, ShouldBeNil)
+				So(expression.Conditions, ShouldResemble, Filter{
+					{Field: "not", Operator: FilterEqual, Values: []string{"1"}},
+				})
+			})
+
+			Convey("should reject invalid expressions", func() {
+				invalid := map[string]string{
+					"filter[or]=open":                                     "filter[or]",
+					"filter[or][0]=open":                                  "filter[or][0]",
+					"filter[or][a][status]=open":                          "filter[or][a][status]",
+					"filter[or][-1][status]=open":                         "filter[or][-1][status]",
+					"filter[not]=open":                                    "filter[not]",
+					"filter[not][name]=bob":                               "filter[not][name]",
+					"filter[and][0][age][gt]=18":                          "filter[and][0][age][gt]",
+					"filter[or][0][status]=a&filter[or][0][status][eq]=b": "filter[or][0][status][eq]",
+				}
+				for q, param := range invalid {
+					_, err := parseExpression(q)
+					So(err, ShouldNotBeNil)
+					So(err.Status, ShouldEqual, http.StatusBadRequest)
+					So(err.Source.Parameter, ShouldEqual, param)
+				}
+			})
+		})
+	})
+}
@@ -0,0 +1,181 @@
+package jsh
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Message keys of the errors created by the convenience constructors. The key of
+// the title of an error is ErrorTitleKey of its code.
+const (
+	MessageBadRequest            = messageErrorPrefix + CodeBadRequest
+	MessageInvalidQueryParameter = messageErrorPrefix + CodeInvalidQueryParameter
+	MessageInvalidHeader         = messageErrorPrefix + CodeInvalidHeader
+	MessageForbidden             = messageErrorPrefix + CodeForbidden
+	MessageNotFound              = messageErrorPrefix + CodeNotFound
+	MessageNotFoundDetail        = "error.not_found.detail"
+	MessageSpecificationError    = messageErrorPrefix + CodeSpecificationError
+	MessageNotAcceptable         = messageErrorPrefix + CodeNotAcceptable
+	MessageUnsupportedMediaType  = messageErrorPrefix + CodeUnsupportedMediaType
+	MessageConflict              = messageErrorPrefix + CodeConflict
+	MessageConflictTypeDetail    = "error.conflict.type"
+	MessageConflictIDDetail      = "error.conflict.id"
+	MessageMissingTopLevelMember = messageErrorPrefix + CodeMissingTopLevelMember
+	MessageTopLevelDetail        = "error.top_level.detail"
+	MessageInvalidAttribute      = messageErrorPrefix + CodeInvalidAttribute
+	MessageInvalidRelationship   = messageErrorPrefix + CodeInvalidRelationship
+	MessageInvalidOperation      = messageErrorPrefix + CodeInvalidOperation
+	MessageInternalServerError   = messageErrorPrefix + CodeInternalServerError
+	MessageInternalServerDetail  = "error.internal_server_error.detail"
+	MessageNotImplemented        = messageErrorPrefix + CodeNotImplemented
+	// MessageValidationPrefix prefixes the name of the govalidator validator that rejected
+	// an attribute, e.g. "validation.email". The attribute name is the message argument.
+	MessageValidationPrefix = "validation."
+	// Keys of UnresolvedLinkageError, the detail has the linked resource as message argument.
+	MessageUnresolvedLinkage       = messageErrorPrefix + CodeUnresolvedLinkage
+	MessageUnresolvedLinkageDetail = "error.unresolved_linkage.detail"
+)
+
+// messageErrorPrefix prefixes the error code in the message key of an error title.
+const messageErrorPrefix = "error."
+
+// ErrorTitleKey returns the message key of the title of the errors with the given code,
+// e.g. "error.not_found" for CodeNotFound. It also applies to the codes of the errors
+// registered by applications: "error.quota_exceeded" for "quota_exceeded".
+func ErrorTitleKey(code string) string {
+	return messageErrorPrefix + code
+}
+
+// DefaultLocale is the locale used when none of the locales accepted by the client
+// has a translation for a message.
+var DefaultLocale = "en"
+
+/*
+Catalog contains the translated message formats by locale and message key. Formats
+are rendered with fmt.Sprintf and the message arguments, use explicit argument indexes
+such as "%[2]s" to reorder them.
+*/
+type Catalog map[string]map[string]string
+
+/*
+Messages is the message catalog used by Send to localize errors. Add your translations
+at initialization:
+
+	jsh.Messages.Add("fr", map[string]string{
+		jsh.MessageInvalidAttribute: "Attribut invalide",
+		"validation.email":         "%s n'est pas une adresse email valide",
+	})
+
+Messages without translation keep the English title and detail of the error.
+*/
+var Messages = Catalog{}
+
+// Add adds the message formats for the given locale, replacing existing ones with the same key.
+func (c Catalog) Add(locale string, messages map[string]string) {
+	locale = strings.ToLower(locale)
+	if c[locale] == nil {
+		c[locale] = map[string]string{}
+	}
+	for key, format := range messages {
+		c[locale][key] = format
+	}
+}
+
+// Translate renders the message in the given locale, falling back to DefaultLocale.
+// It returns false if there is no translation for the message key.
+func (c Catalog) Translate(locale, key string, args ...interface{}) (string, bool) {
+	if key == "" {
+		return "", false
+	}
+	for _, candidate := range []string{strings.ToLower(locale), DefaultLocale} {
+		if format, ok := c[candidate][key]; ok {
+			return fmt.Sprintf(format, args...), true
+		}
+	}
+	return "", false
+}
+
+/*
+NegotiateLocale returns the catalog locale that best matches the Accept-Language
+header of the request, by decreasing quality value. A language range such as
+"fr-CH" matches the "fr" locale when there is no "fr-ch" locale. DefaultLocale is
+returned if no locale matches.
+*/
+func NegotiateLocale(r *http.Request, catalog Catalog) string {
+	type languageRange struct {
+		tag     string
+		quality float64
+	}
+
+	var ranges []languageRange
+	for _, header := range r.Header[http.CanonicalHeaderKey("Accept-Language")] {
+		for _, part := range strings.Split(header, ",") {
+			params := strings.Split(part, ";")
+			tag := strings.ToLower(strings.TrimSpace(params[0]))
+			quality := 1.0
+			for _, param := range params[1:] {
+				param = strings.TrimSpace(param)
+				if strings.HasPrefix(param, "q=") {
+					if q, err := strconv.ParseFloat(param[2:], 64); err == nil {
+						quality = q
+					}
+				}
+			}
+			if tag != "" && tag != "*" && quality > 0 {
+				ranges = append(ranges, languageRange{tag, quality})
+			}
+		}
+	}
+	sort.SliceStable(ranges, func(i, j int) bool {
+		return ranges[i].quality > ranges[j].quality
+	})
+
+	for _, lang := range ranges {
+		if _, ok := catalog[lang.tag]; ok {
+			return lang.tag
+		}
+		if i := strings.Index(lang.tag, "-"); i > 0 {
+			if _, ok := catalog[lang.tag[:i]]; ok {
+				return lang.tag[:i]
+			}
+		}
+	}
+	return DefaultLocale
+}
+
+/*
+Localize replaces the title and detail of the error with their translation in the
+given locale, when the error has message keys and the catalog has a translation.
+*/
+func (e *Error) Localize(locale string, catalog Catalog) {
+	if title, ok := catalog.Translate(locale, e.TitleKey, e.TitleArgs...); ok {
+		e.Title = title
+	}
+	if detail, ok := catalog.Translate(locale, e.DetailKey, e.DetailArgs...); ok {
+		e.Detail = detail
+	}
+}
+
+// localizeDocument localizes the errors of the document in the locale negotiated
+// with the client, and sets the Content-Language of the response accordingly. The
+// errors are replaced by localized copies, so that errors shared between requests,
+// such as package-level errors, keep their original title and detail.
+func localizeDocument(w http.ResponseWriter, r *http.Request, document *Document) {
+	if !document.HasErrors() {
+		return
+	}
+	locale := NegotiateLocale(r, Messages)
+	localized := make(ErrorList, len(document.Errors))
+	for i, err := range document.Errors {
+		copied := *err
+		copied.Localize(locale, Messages)
+		localized[i] = &copied
+	}
+	document.Errors = localized
+	if _, ok := Messages[locale]; ok {
+		w.Header().Set("Content-Language", locale)
+	}
+}
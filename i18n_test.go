@@ -0,0 +1,109 @@
+package jsh
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestI18n(t *testing.T) {
+
+	Convey("Localization Tests", t, func() {
+
+		Messages.Add("fr", map[string]string{
+			MessageNotFound:       "Introuvable",
+			MessageNotFoundDetail: "Aucune ressource de type '%s' avec l'ID %s",
+			"validation.email":    "%s doit être une adresse email",
+		})
+		Messages.Add("de", map[string]string{
+			MessageNotFound: "Nicht gefunden",
+		})
+		Reset(func() {
+			delete(Messages, "fr")
+			delete(Messages, "de")
+		})
+
+		req, err := http.NewRequest("GET", "/tests/1", nil)
+		So(err, ShouldBeNil)
+
+		Convey("->NegotiateLocale()", func() {
+
+			Convey("should pick the best matching locale by quality", func() {
+				req.Header.Set("Accept-Language", "it;q=0.9, de;q=0.5, fr-CH;q=0.8, *;q=0.1")
+				So(NegotiateLocale(req, Messages), ShouldEqual, "fr")
+			})
+
+			Convey("should fall back to the default locale", func() {
+				So(NegotiateLocale(req, Messages), ShouldEqual, DefaultLocale)
+
+				req.Header.Set("Accept-Language", "it, fr;q=0")
+				So(NegotiateLocale(req, Messages), ShouldEqual, DefaultLocale)
+			})
+		})
+
+		Convey("->Localize()", func() {
+
+			Convey("should translate the title and detail with arguments", func() {
+				notFound := NotFound("tests", "1")
+				notFound.Localize("fr", Messages)
+				So(notFound.Title, ShouldEqual, "Introuvable")
+				So(notFound.Detail, ShouldEqual, "Aucune ressource de type 'tests' avec l'ID 1")
+			})
+
+			Convey("should keep untranslated messages", func() {
+				notFound := NotFound("tests", "1")
+				notFound.Localize("de", Messages)
+				So(notFound.Title, ShouldEqual, "Nicht gefunden")
+				So(notFound.Detail, ShouldEqual, "No resource of type 'tests' exists for ID: 1")
+
+				notFound = NotFound("tests", "1")
+				notFound.Localize("it", Messages)
+				So(notFound.Title, ShouldEqual, "Not Found")
+			})
+
+			Convey("should translate validation errors", func() {
+				model := &struct {
+					Email string `json:"email" valid:"email"`
+				}{Email: "nope"}
+				errors := validateInput(model)
+				So(errors, ShouldHaveLength, 1)
+				So(errors[0].DetailKey, ShouldEqual, "validation.email")
+
+				errors[0].Localize("fr", Messages)
+				So(errors[0].Detail, ShouldEqual, "email doit être une adresse email")
+			})
+		})
+
+		Convey("->Send()", func() {
+			req.Header.Set("Accept-Language", "fr-FR, en;q=0.5")
+			writer := httptest.NewRecorder()
+
+			sendErr := Send(writer, req, NotFound("tests", "1"))
+			So(sendErr, ShouldBeNil)
+			So(writer.Header().Get("Content-Language"), ShouldEqual, "fr")
+
+			var document struct {
+				Errors []*Error `json:"errors"`
+			}
+			So(json.Unmarshal(writer.Body.Bytes(), &document), ShouldBeNil)
+			So(document.Errors, ShouldHaveLength, 1)
+			So(document.Errors[0].Title, ShouldEqual, "Introuvable")
+		})
+
+		Convey("should leave the sent errors untouched", func() {
+			notFound := NotFound("tests", "1")
+			req.Header.Set("Accept-Language", "fr")
+			So(Send(httptest.NewRecorder(), req, notFound), ShouldBeNil)
+			So(notFound.Title, ShouldEqual, "Not Found")
+
+			req.Header.Set("Accept-Language", "en")
+			writer := httptest.NewRecorder()
+			So(Send(writer, req, notFound), ShouldBeNil)
+			So(writer.Body.String(), ShouldContainSubstring, `"title":"Not Found"`)
+			So(writer.Body.String(), ShouldNotContainSubstring, "Introuvable")
+		})
+	})
+}
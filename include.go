@@ -0,0 +1,127 @@
+package jsh
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+const (
+	paramInclude = "include"
+	pathSep      = "."
+)
+
+/*
+Include is a tree of relationship paths requested through the include query parameter:
+http://jsonapi.org/format/#fetching-includes
+
+Each key is the name of a relationship to include and maps to the relationships
+to include from the related resources. For instance, "include=author,comments.author"
+is parsed as:
+
+	jsh.Include{
+		"author":   jsh.Include{},
+		"comments": jsh.Include{"author": jsh.Include{}},
+	}
+*/
+type Include map[string]Include
+
+/*
+ParseInclude parses the include query parameter of the request and validates every
+requested path against the allowed relationship paths of the resource type.
+A path is allowed if it is part of one of the allowed paths, meaning that allowing
+"comments.author" also allows "comments".
+
+	include, err := jsh.ParseInclude(r, "author", "comments.author")
+	if err != nil {
+		jsh.Send(w, r, err)
+		return
+	}
+
+A ParameterError is returned for malformed or disallowed paths.
+*/
+func ParseInclude(r *http.Request, allowed ...string) (Include, *Error) {
+	values := query(r)[paramInclude]
+	if len(values) > 1 {
+		return nil, ParameterError("Query parameter 'include' must be set only once", paramInclude)
+	}
+
+	include := Include{}
+	if len(values) == 0 {
+		return include, nil
+	}
+	paths, ok := splitList(values[0])
+	if !ok {
+		return nil, ParameterError("Empty relationship path", paramInclude)
+	}
+	for _, path := range paths {
+		names := strings.Split(path, pathSep)
+		for _, name := range names {
+			if name == "" {
+				return nil, ParameterError(fmt.Sprintf("Malformed relationship path '%s'", path), paramInclude)
+			}
+		}
+		if !isAllowedPath(path, allowed) {
+			return nil, ParameterError(fmt.Sprintf("Inclusion of '%s' is not supported", path), paramInclude)
+		}
+		include.add(names)
+	}
+	return include, nil
+}
+
+// Has returns true if the given relationship path (e.g. "comments.author") was requested.
+func (i Include) Has(path string) bool {
+	node := i
+	for _, name := range strings.Split(path, pathSep) {
+		next, ok := node[name]
+		if !ok {
+			return false
+		}
+		node = next
+	}
+	return true
+}
+
+// Get returns the relationships to include from the given relationship, or nil if
+// the relationship was not requested.
+func (i Include) Get(name string) Include {
+	return i[name]
+}
+
+// Paths returns every requested relationship path in lexical order, including
+// the intermediate ones.
+func (i Include) Paths() []string {
+	var paths []string
+	for name, node := range i {
+		paths = append(paths, name)
+		for _, path := range node.Paths() {
+			paths = append(paths, name+pathSep+path)
+		}
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// add adds the relationship path described by names to the tree.
+func (i Include) add(names []string) {
+	node := i
+	for _, name := range names {
+		next, ok := node[name]
+		if !ok {
+			next = Include{}
+			node[name] = next
+		}
+		node = next
+	}
+}
+
+// isAllowedPath returns true if the path is equal to or a prefix of one of the allowed paths.
+func isAllowedPath(path string, allowed []string) bool {
+	for _, a := range allowed {
+		if a == path || strings.HasPrefix(a, path+pathSep) {
+			return true
+		}
+	}
+	return false
+}
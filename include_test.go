@@ -0,0 +1,74 @@
+package jsh
+
+import (
+	"net/http"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestInclude(t *testing.T) {
+
+	Convey("Include Tests", t, func() {
+
+		allowed := []string{"author", "comments.author"}
+
+		Convey("->ParseInclude()", func() {
+
+			Convey("should parse relationship paths into a tree", func() {
+				req, err := http.NewRequest("GET", "/articles?include=author,comments.author", nil)
+				So(err, ShouldBeNil)
+
+				include, parseErr := ParseInclude(req, allowed...)
+				So(parseErr, ShouldBeNil)
+				So(include, ShouldResemble, Include{
+					"author":   Include{},
+					"comments": Include{"author": Include{}},
+				})
+				So(include.Paths(), ShouldResemble, []string{"author", "comments", "comments.author"})
+				So(include.Has("comments.author"), ShouldBeTrue)
+				So(include.Has("comments.article"), ShouldBeFalse)
+				So(include.Get("comments"), ShouldContainKey, "author")
+			})
+
+			Convey("should return an empty tree without include parameter", func() {
+				req, err := http.NewRequest("GET", "/articles", nil)
+				So(err, ShouldBeNil)
+
+				include, parseErr := ParseInclude(req)
+				So(parseErr, ShouldBeNil)
+				So(include, ShouldBeEmpty)
+			})
+
+			Convey("should accept intermediate paths of allowed paths", func() {
+				req, err := http.NewRequest("GET", "/articles?include=comments", nil)
+				So(err, ShouldBeNil)
+
+				include, parseErr := ParseInclude(req, allowed...)
+				So(parseErr, ShouldBeNil)
+				So(include.Has("comments"), ShouldBeTrue)
+			})
+
+			Convey("should reject disallowed paths", func() {
+				req, err := http.NewRequest("GET", "/articles?include=author.comments", nil)
+				So(err, ShouldBeNil)
+
+				_, parseErr := ParseInclude(req, allowed...)
+				So(parseErr, ShouldNotBeNil)
+				So(parseErr.Status, ShouldEqual, http.StatusBadRequest)
+				So(parseErr.Source.Parameter, ShouldEqual, "include")
+			})
+
+			Convey("should reject malformed paths", func() {
+				for _, q := range []string{"include=author,,comments", "include=comments..author", "include=author&include=comments"} {
+					req, err := http.NewRequest("GET", "/articles?"+q, nil)
+					So(err, ShouldBeNil)
+
+					_, parseErr := ParseInclude(req, allowed...)
+					So(parseErr, ShouldNotBeNil)
+					So(parseErr.Source.Parameter, ShouldEqual, "include")
+				}
+			})
+		})
+	})
+}
@@ -0,0 +1,145 @@
+package jsh
+
+import "fmt"
+
+/*
+LIDResolver rewrites the local identifiers (lid) of a request document to the IDs
+assigned by the server: http://jsonapi.org/format/1.1/#document-resource-object-identification
+
+Register the ID of each resource once it is created, then resolve the references
+of the remaining resources before processing them:
+
+	resolver := jsh.NewLIDResolver()
+	for i, op := range ops {
+		if err := resolver.ResolveOperation(i, op); err != nil {
+			jsh.Send(w, r, err)
+			return
+		}
+		object, err := apply(op)
+		...
+		resolver.Set(object.Type, op.Object().LID, object.ID)
+	}
+*/
+type LIDResolver struct {
+	ids map[resourceKey]string
+}
+
+// NewLIDResolver creates a resolver without any known local identifier.
+func NewLIDResolver() *LIDResolver {
+	return &LIDResolver{ids: map[resourceKey]string{}}
+}
+
+// Set registers the ID assigned by the server to the resource with the given local identifier.
+func (r *LIDResolver) Set(resourceType, lid, id string) {
+	if lid == "" {
+		return
+	}
+	r.ids[resourceKey{Type: resourceType, LID: lid}] = id
+}
+
+// Get returns the ID assigned to the resource with the given local identifier, if any.
+func (r *LIDResolver) Get(resourceType, lid string) (string, bool) {
+	id, ok := r.ids[resourceKey{Type: resourceType, LID: lid}]
+	return id, ok
+}
+
+/*
+Resolve rewrites every local identifier of the document: primary data, included
+resources and atomic operations. The local identifier of a resource object is only
+rewritten once its ID has been set, while every resource linkage must be resolvable.
+*/
+func (r *LIDResolver) Resolve(document *Document) *Error {
+	for _, object := range document.Data {
+		if err := r.ResolveObject(object); err != nil {
+			return err
+		}
+	}
+	for _, object := range document.Included {
+		if err := r.ResolveObject(object); err != nil {
+			return err
+		}
+	}
+	for i, op := range document.Operations {
+		if err := r.ResolveOperation(i, op); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ResolveObject rewrites the local identifier of the object and of its relationship linkage.
+// An error is returned if the linkage references an unknown local identifier.
+func (r *LIDResolver) ResolveObject(object *Object) *Error {
+	if id, ok := r.lookup(object.Type, object.LID); ok && object.ID == "" {
+		object.ID = id
+		object.LID = ""
+	}
+	for name, relationship := range object.Relationships {
+		if relationship == nil {
+			continue
+		}
+		for _, linkage := range relationship.Data {
+			if !r.resolve(linkage) {
+				return RelationshipError(unknownLID(linkage.Type, linkage.LID), name+"/data/lid")
+			}
+		}
+	}
+	return nil
+}
+
+// ResolveOperation rewrites the local identifiers of the operation at the given index:
+// its target reference and its data. Errors point to the operation.
+func (r *LIDResolver) ResolveOperation(index int, op *Operation) *Error {
+	if op.Ref != nil && op.Ref.LID != "" {
+		id, ok := r.lookup(op.Ref.Type, op.Ref.LID)
+		if !ok {
+			return operationError(index, "/ref/lid", unknownLID(op.Ref.Type, op.Ref.LID))
+		}
+		op.Ref.ID = id
+		op.Ref.LID = ""
+	}
+
+	for _, object := range op.Data {
+		if op.IsRelationship() {
+			// Relationship operations only contain resource linkage
+			linkage := object.ToIDObject()
+			if !r.resolve(linkage) {
+				return operationError(index, "/data/lid", unknownLID(linkage.Type, linkage.LID))
+			}
+			object.ID, object.LID = linkage.ID, linkage.LID
+			continue
+		}
+		if err := r.ResolveObject(object); err != nil {
+			return OperationError(index, err)
+		}
+	}
+	return nil
+}
+
+// lookup returns the ID of the local identifier, it is not found for an empty lid.
+func (r *LIDResolver) lookup(resourceType, lid string) (string, bool) {
+	if lid == "" {
+		return "", false
+	}
+	return r.Get(resourceType, lid)
+}
+
+// resolve rewrites the local identifier of the resource linkage. It returns false
+// if the linkage only has an unknown local identifier.
+func (r *LIDResolver) resolve(linkage *IDObject) bool {
+	if linkage.LID == "" || linkage.ID != "" {
+		return true
+	}
+	id, ok := r.lookup(linkage.Type, linkage.LID)
+	if !ok {
+		return false
+	}
+	linkage.ID = id
+	linkage.LID = ""
+	return true
+}
+
+// unknownLID returns the error detail of an unresolved local identifier.
+func unknownLID(resourceType, lid string) string {
+	return fmt.Sprintf("Unknown local identifier '%s' for type '%s'", lid, resourceType)
+}
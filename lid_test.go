@@ -0,0 +1,162 @@
+package jsh
+
+import (
+	"net/http"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestLID(t *testing.T) {
+
+	Convey("Local Identifier Tests", t, func() {
+
+		Convey("->Validate()", func() {
+			req := &http.Request{Method: "PATCH"}
+
+			Convey("should accept a resource identifier with a lid", func() {
+				linkage := &IDObject{Type: "people", LID: "a"}
+				So(linkage.Validate(req, false), ShouldBeNil)
+			})
+
+			Convey("should reject a resource identifier without id nor lid", func() {
+				linkage := &IDObject{Type: "people"}
+				err := linkage.Validate(req, false)
+				So(err, ShouldNotBeNil)
+				So(err.Status, ShouldEqual, http.StatusNotAcceptable)
+			})
+
+			Convey("should accept an object with a lid when it is created", func() {
+				object := &Object{Type: "people", LID: "a"}
+				So(object.Validate(&http.Request{Method: "POST"}, false), ShouldBeNil)
+			})
+
+			Convey("should reject an object with only a lid when it is not created", func() {
+				object := &Object{Type: "people", LID: "a"}
+				err := object.Validate(req, false)
+				So(err, ShouldNotBeNil)
+				So(err.Status, ShouldEqual, http.StatusNotAcceptable)
+			})
+		})
+
+		Convey("->ParseObject()", func() {
+
+			Convey("should accept a lid in place of the id", func() {
+				req, err := testRequest([]byte(`{"data": {"type": "people", "lid": "a", "relationships": {
+					"friend": {"data": {"type": "people", "lid": "b"}}
+				}}}`))
+				So(err, ShouldBeNil)
+				req.Method = "POST"
+
+				object, parseErr := ParseObject(req)
+				So(parseErr, ShouldBeNil)
+				So(object.LID, ShouldEqual, "a")
+				So(object.Relationships["friend"].Data[0].LID, ShouldEqual, "b")
+			})
+
+			Convey("should require the id of an updated object", func() {
+				req, err := testRequest([]byte(`{"data": {"type": "people", "lid": "a", "relationships": {
+					"friend": {"data": {"type": "people", "lid": "b"}}
+				}}}`))
+				So(err, ShouldBeNil)
+				req.Method = "PATCH"
+
+				_, parseErr := ParseObject(req)
+				So(parseErr, ShouldNotBeNil)
+				So(parseErr.Status, ShouldEqual, 422)
+				So(parseErr.Source.Pointer, ShouldEqual, "/data/id")
+			})
+
+			Convey("should require the ids of a list that does not create resources", func() {
+				req, err := testRequest([]byte(`{"data": [{"type": "people", "lid": "a"}, {"type": "people", "lid": "b"}]}`))
+				So(err, ShouldBeNil)
+				req.Method = "PATCH"
+
+				_, parseErr := ParseList(req)
+				So(parseErr, ShouldNotBeNil)
+
+				req, err = testRequest([]byte(`{"data": [{"type": "people", "lid": "a"}, {"type": "people", "lid": "b"}]}`))
+				So(err, ShouldBeNil)
+				req.Method = "POST"
+
+				list, parseErr := ParseList(req)
+				So(parseErr, ShouldBeNil)
+				So(list, ShouldHaveLength, 2)
+			})
+
+			Convey("should reject a resource linkage without id nor lid", func() {
+				req, err := testRequest([]byte(`{"data": {"type": "people", "id": "1", "relationships": {
+					"friend": {"data": {"type": "people"}}
+				}}}`))
+				So(err, ShouldBeNil)
+
+				_, parseErr := ParseObject(req)
+				So(parseErr, ShouldNotBeNil)
+				So(parseErr.Source.Pointer, ShouldEqual, "/data/relationships/friend/data/id")
+			})
+		})
+
+		Convey("->ParseRelationship()", func() {
+			req, err := testRequest([]byte(`{"data": {"type": "people", "lid": "a"}}`))
+			So(err, ShouldBeNil)
+
+			linkage, parseErr := ParseRelationship(req)
+			So(parseErr, ShouldBeNil)
+			So(linkage, ShouldResemble, &IDObject{Type: "people", LID: "a"})
+		})
+
+		Convey("->LIDResolver", func() {
+			resolver := NewLIDResolver()
+			resolver.Set("people", "a", "1")
+
+			id, ok := resolver.Get("people", "a")
+			So(ok, ShouldBeTrue)
+			So(id, ShouldEqual, "1")
+			_, ok = resolver.Get("articles", "a")
+			So(ok, ShouldBeFalse)
+
+			Convey("should rewrite the lid of objects and linkage", func() {
+				object := &Object{Type: "people", LID: "a", Relationships: map[string]*Relationship{
+					"friend": {Data: IDList{{Type: "people", LID: "a"}, {Type: "people", ID: "2"}}},
+				}}
+				So(resolver.ResolveObject(object), ShouldBeNil)
+				So(object.ID, ShouldEqual, "1")
+				So(object.LID, ShouldBeEmpty)
+				So(object.Relationships["friend"].Data, ShouldResemble, IDList{
+					NewIDObject("people", "1"),
+					NewIDObject("people", "2"),
+				})
+			})
+
+			Convey("should keep the lid of objects that are not created yet", func() {
+				object := &Object{Type: "people", LID: "b"}
+				So(resolver.ResolveObject(object), ShouldBeNil)
+				So(object.ID, ShouldBeEmpty)
+				So(object.LID, ShouldEqual, "b")
+			})
+
+			Convey("should reject unknown linkage lid", func() {
+				object := &Object{Type: "people", ID: "3", Relationships: map[string]*Relationship{
+					"friend": {Data: IDList{{Type: "people", LID: "b"}}},
+				}}
+				err := resolver.ResolveObject(object)
+				So(err, ShouldNotBeNil)
+				So(err.Source.Pointer, ShouldEqual, "/data/relationships/friend/data/lid")
+			})
+
+			Convey("should resolve atomic operations", func() {
+				document := &Document{Operations: []*Operation{
+					{Op: OperationUpdate, Ref: &OperationRef{Type: "people", LID: "a", Relationship: "friend"},
+						Data: List{{Type: "people", LID: "a"}}},
+					{Op: OperationRemove, Ref: &OperationRef{Type: "people", LID: "c"}},
+				}}
+				err := resolver.Resolve(document)
+				So(err, ShouldNotBeNil)
+				So(err.Source.Pointer, ShouldEqual, "/atomic:operations/1/ref/lid")
+
+				So(document.Operations[0].Ref.ID, ShouldEqual, "1")
+				So(document.Operations[0].Linkage(), ShouldResemble, IDList{NewIDObject("people", "1")})
+			})
+		})
+	})
+}
@@ -5,10 +5,93 @@ import (
 	"fmt"
 )
 
-// Links is a top-level document field
+/*
+Links is a links object, used at the top level of a document and in relationships.
+The pagination links (first, prev, next and last) are only meant to be used for
+collections, see NewPaginationLinks. Any other link, such as "describedby", "about"
+or an application specific one, is stored in Other:
+
+	links.Set("describedby", jsh.NewLink("/schemas/articles"))
+*/
 type Links struct {
 	Self    *Link `json:"self,omitempty"`
 	Related *Link `json:"related,omitempty"`
+	First   *Link `json:"first,omitempty"`
+	Prev    *Link `json:"prev,omitempty"`
+	Next    *Link `json:"next,omitempty"`
+	Last    *Link `json:"last,omitempty"`
+	// Other holds every other link by name
+	Other map[string]*Link `json:"-"`
+}
+
+// Get returns the link of the given name, or nil if it is not set.
+func (l *Links) Get(name string) *Link {
+	if field := l.field(name); field != nil {
+		return *field
+	}
+	return l.Other[name]
+}
+
+// Set sets the link of the given name.
+func (l *Links) Set(name string, link *Link) {
+	if field := l.field(name); field != nil {
+		*field = link
+		return
+	}
+	if l.Other == nil {
+		l.Other = map[string]*Link{}
+	}
+	l.Other[name] = link
+}
+
+// field returns a pointer to the struct field of the given link name, or nil if
+// the link is not one of the predefined ones.
+func (l *Links) field(name string) **Link {
+	switch name {
+	case "self":
+		return &l.Self
+	case "related":
+		return &l.Related
+	case "first":
+		return &l.First
+	case "prev":
+		return &l.Prev
+	case "next":
+		return &l.Next
+	case "last":
+		return &l.Last
+	}
+	return nil
+}
+
+// MarshalJSON implements the Marshaler interface for Links.
+func (l *Links) MarshalJSON() ([]byte, error) {
+	links := map[string]*Link{}
+	for name, link := range l.Other {
+		if link != nil {
+			links[name] = link
+		}
+	}
+	for _, name := range []string{"self", "related", "first", "prev", "next", "last"} {
+		if link := *l.field(name); link != nil {
+			links[name] = link
+		}
+	}
+	return json.Marshal(links)
+}
+
+// UnmarshalJSON implements the Unmarshaler interface for Links.
+func (l *Links) UnmarshalJSON(data []byte) error {
+	links := map[string]*Link{}
+	err := json.Unmarshal(data, &links)
+	if err != nil {
+		return err
+	}
+	*l = Links{}
+	for name, link := range links {
+		l.Set(name, link)
+	}
+	return nil
 }
 
 // NewRelationshipLinks creates a new pair of relationship links encoded as a string.
@@ -19,11 +102,25 @@ func NewRelationshipLinks(id interface{}, resource, name string) *Links {
 	}
 }
 
-// Link is a resource link that can encode as a string or as an object
-// as per the JSON API specification.
+/*
+Link is a resource link that can encode as a string or as an object
+as per the JSON API specification: http://jsonapi.org/format/1.1/#document-links-link-object
+
+A link is encoded as a string when only its HREF is set.
+*/
 type Link struct {
-	HREF string                 `json:"href,omitempty"`
-	Meta map[string]interface{} `json:"meta,omitempty"`
+	HREF string `json:"href,omitempty"`
+	// Rel is the link relation type
+	Rel string `json:"rel,omitempty"`
+	// DescribedBy links to a description document (e.g. a JSON Schema) of the link target
+	DescribedBy *Link `json:"describedby,omitempty"`
+	// Title is a human-readable label of the link target
+	Title string `json:"title,omitempty"`
+	// Type is the media type of the link target
+	Type string `json:"type,omitempty"`
+	// HrefLang lists the languages of the link target
+	HrefLang HrefLang               `json:"hreflang,omitempty"`
+	Meta     map[string]interface{} `json:"meta,omitempty"`
 }
 
 // NewLink creates a new link encoded as a string.
@@ -57,7 +154,7 @@ func NewMetaLink(href string, meta map[string]interface{}) *Link {
 
 // MarshalJSON implements the Marshaler interface for Link.
 func (l *Link) MarshalJSON() ([]byte, error) {
-	if l.Meta == nil {
+	if l.isString() {
 		return json.Marshal(l.HREF)
 	}
 	// Create a sub-type here so when we call Marshal below, we don't recursively
@@ -86,3 +183,38 @@ func (l *Link) UnmarshalJSON(data []byte) error {
 	*l = Link(link)
 	return nil
 }
+
+// isString returns true if the link only has an HREF and can be encoded as a string.
+func (l *Link) isString() bool {
+	return l.Meta == nil && l.Rel == "" && l.DescribedBy == nil &&
+		l.Title == "" && l.Type == "" && len(l.HrefLang) == 0
+}
+
+// HrefLang is the list of languages of a link target, encoded as a string
+// when it contains a single language.
+type HrefLang []string
+
+// MarshalJSON implements the Marshaler interface for HrefLang.
+func (h HrefLang) MarshalJSON() ([]byte, error) {
+	if len(h) == 1 {
+		return json.Marshal(h[0])
+	}
+	return json.Marshal([]string(h))
+}
+
+// UnmarshalJSON implements the Unmarshaler interface for HrefLang.
+func (h *HrefLang) UnmarshalJSON(data []byte) error {
+	var lang string
+	err := json.Unmarshal(data, &lang)
+	if err == nil {
+		*h = HrefLang{lang}
+		return nil
+	}
+	var langs []string
+	err = json.Unmarshal(data, &langs)
+	if err != nil {
+		return err
+	}
+	*h = HrefLang(langs)
+	return nil
+}
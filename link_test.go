@@ -79,5 +79,70 @@ func TestLink(t *testing.T) {
 				So(l.Meta["count"], ShouldEqual, 10)
 			})
 		})
+
+		Convey("Link object members", func() {
+
+			Convey("should marshal as an object when a link object member is set", func() {
+				l := NewLink("/articles/1")
+				l.Rel = "canonical"
+				l.Title = "Article"
+				l.HrefLang = HrefLang{"fr"}
+
+				jData, err := json.Marshal(l)
+				So(err, ShouldBeNil)
+				So(string(jData), ShouldEqual, `{"href":"/articles/1","rel":"canonical","title":"Article","hreflang":"fr"}`)
+			})
+
+			Convey("should unmarshal every link object member", func() {
+				jLink := `{"href": "/articles/1", "rel": "canonical", "describedby": "/schemas/articles",
+					"title": "Article", "type": "text/html", "hreflang": ["fr", "de"]}`
+
+				l := Link{}
+				err := l.UnmarshalJSON([]byte(jLink))
+				So(err, ShouldBeNil)
+				So(l.Rel, ShouldEqual, "canonical")
+				So(l.DescribedBy, ShouldResemble, NewLink("/schemas/articles"))
+				So(l.Title, ShouldEqual, "Article")
+				So(l.Type, ShouldEqual, "text/html")
+				So(l.HrefLang, ShouldResemble, HrefLang{"fr", "de"})
+			})
+		})
+	})
+}
+
+func TestLinks(t *testing.T) {
+
+	Convey("Links Tests", t, func() {
+
+		Convey("->Set()", func() {
+			links := &Links{}
+			links.Set("self", NewLink("/articles"))
+			links.Set("describedby", NewLink("/schemas/articles"))
+
+			So(links.Self, ShouldResemble, NewLink("/articles"))
+			So(links.Other, ShouldContainKey, "describedby")
+			So(links.Get("describedby"), ShouldResemble, NewLink("/schemas/articles"))
+			So(links.Get("about"), ShouldBeNil)
+		})
+
+		Convey("->MarshalJSON()", func() {
+			links := &Links{
+				Self:  NewLink("/articles"),
+				Other: map[string]*Link{"about": NewLink("/about")},
+			}
+
+			jData, err := json.Marshal(links)
+			So(err, ShouldBeNil)
+			So(string(jData), ShouldEqual, `{"about":"/about","self":"/articles"}`)
+		})
+
+		Convey("->UnmarshalJSON()", func() {
+			links := &Links{}
+			err := json.Unmarshal([]byte(`{"self": "/articles", "next": null, "custom": {"href": "/custom"}}`), links)
+			So(err, ShouldBeNil)
+			So(links.Self.HREF, ShouldEqual, "/articles")
+			So(links.Next, ShouldBeNil)
+			So(links.Get("custom").HREF, ShouldEqual, "/custom")
+		})
 	})
 }
@@ -0,0 +1,131 @@
+package jsh
+
+import "errors"
+
+// ErrorMapper converts an error to a jsh error (*Error or ErrorList). It returns nil
+// if it does not handle the given error.
+type ErrorMapper func(err error) ErrorType
+
+/*
+ErrorMappers is an ordered registry of error mappers, used to convert the errors of
+handlers to jsh errors. The first mapper handling an error wins.
+*/
+type ErrorMappers struct {
+	mappers []ErrorMapper
+}
+
+/*
+DefaultErrorMappers is the registry used by ConvertError. Register the mappings of
+your application at initialization:
+
+	func init() {
+		jsh.DefaultErrorMappers.Is(sql.ErrNoRows, func(err error) *jsh.Error {
+			return jsh.NotFound("resource", "")
+		})
+		jsh.DefaultErrorMappers.Register(jsh.AsMapper(func(err *ValidationError) *jsh.Error {
+			return jsh.InputError(err.Message, err.Field)
+		}))
+	}
+*/
+var DefaultErrorMappers = &ErrorMappers{}
+
+// Register adds the mapper after the ones already registered.
+func (m *ErrorMappers) Register(mapper ErrorMapper) {
+	m.mappers = append(m.mappers, mapper)
+}
+
+// Is registers a mapper for the errors matching target according to errors.Is.
+func (m *ErrorMappers) Is(target error, build func(err error) *Error) {
+	m.Register(func(err error) ErrorType {
+		if !errors.Is(err, target) {
+			return nil
+		}
+		return build(err)
+	})
+}
+
+// AsMapper returns a mapper for the errors that can be converted to T according to errors.As.
+func AsMapper[T error](build func(err T) *Error) ErrorMapper {
+	return func(err error) ErrorType {
+		var target T
+		if !errors.As(err, &target) {
+			return nil
+		}
+		return build(target)
+	}
+}
+
+/*
+Convert converts the error to a jsh error:
+
+  - errors wrapping an *Error or an ErrorList are unwrapped
+  - other errors are converted by the first registered mapper handling them
+  - otherwise an ISE is returned with the error message set as internal message
+
+The converted error keeps err as its cause (see Error.Unwrap), for logging purposes.
+A nil error is converted to nil.
+*/
+func (m *ErrorMappers) Convert(err error) ErrorType {
+	if err == nil {
+		return nil
+	}
+
+	// Both *Error and ErrorList implement ErrorType, so that a list is found as a whole
+	// before errors.As walks through its errors
+	var jshErr ErrorType
+	if errors.As(err, &jshErr) && !isNilError(jshErr) {
+		return jshErr
+	}
+
+	for _, mapper := range m.mappers {
+		converted := mapper(err)
+		if converted == nil || isNilError(converted) {
+			continue
+		}
+		// Mappers may return shared errors, the cause is set on copies so that it never
+		// leaks from a request to another
+		switch e := converted.(type) {
+		case *Error:
+			return withCause(e, err)
+		case ErrorList:
+			list := make(ErrorList, len(e))
+			for i, listErr := range e {
+				list[i] = withCause(listErr, err)
+			}
+			return list
+		}
+		return converted
+	}
+
+	return ISE(err.Error()).WithCause(err)
+}
+
+/*
+ConvertError converts the error to a jsh error with the default registry, see
+ErrorMappers.Convert.
+
+	if err := store.Save(article); err != nil {
+		jsh.Send(w, r, jsh.ConvertError(err))
+		return
+	}
+*/
+func ConvertError(err error) ErrorType {
+	return DefaultErrorMappers.Convert(err)
+}
+
+// withCause returns a copy of the error with the given cause, unless it already has one.
+func withCause(e *Error, cause error) *Error {
+	if e == nil || e.Cause != nil {
+		return e
+	}
+	c := *e
+	c.Cause = cause
+	return &c
+}
+
+// isNilError returns true if the jsh error is a nil *Error, which a mapper with a
+// *Error result type returns as a non-nil ErrorType.
+func isNilError(err ErrorType) bool {
+	e, ok := err.(*Error)
+	return ok && e == nil
+}
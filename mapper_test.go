@@ -0,0 +1,120 @@
+package jsh
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+type testDomainError struct {
+	Field string
+}
+
+func (e *testDomainError) Error() string {
+	return fmt.Sprintf("invalid %s", e.Field)
+}
+
+func TestErrorMappers(t *testing.T) {
+
+	Convey("Error Mapper Tests", t, func() {
+
+		mappers := &ErrorMappers{}
+		mappers.Is(sql.ErrNoRows, func(err error) *Error {
+			return NotFound("tests", "1")
+		})
+		mappers.Register(AsMapper(func(err *testDomainError) *Error {
+			return InputError("Invalid value", err.Field)
+		}))
+		mappers.Register(func(err error) ErrorType {
+			if !errors.Is(err, context.DeadlineExceeded) {
+				return nil
+			}
+			return ErrorList{InputError("Too slow", "a"), InputError("Too slow", "b")}
+		})
+
+		Convey("->Convert()", func() {
+
+			Convey("should map errors with errors.Is", func() {
+				cause := fmt.Errorf("loading test: %w", sql.ErrNoRows)
+				err := mappers.Convert(cause)
+				So(err.StatusCode(), ShouldEqual, http.StatusNotFound)
+				So(errors.Is(err, sql.ErrNoRows), ShouldBeTrue)
+			})
+
+			Convey("should map errors with errors.As", func() {
+				err := mappers.Convert(fmt.Errorf("saving: %w", &testDomainError{Field: "name"}))
+				So(err.StatusCode(), ShouldEqual, 422)
+
+				var domainErr *testDomainError
+				So(errors.As(err, &domainErr), ShouldBeTrue)
+				So(domainErr.Field, ShouldEqual, "name")
+			})
+
+			Convey("should map errors to a list", func() {
+				err := mappers.Convert(context.DeadlineExceeded)
+				list, ok := err.(ErrorList)
+				So(ok, ShouldBeTrue)
+				So(list, ShouldHaveLength, 2)
+				So(errors.Is(list, context.DeadlineExceeded), ShouldBeTrue)
+			})
+
+			Convey("should not modify shared errors returned by mappers", func() {
+				shared := NotFound("tests", "1")
+				list := ErrorList{InputError("Invalid", "name")}
+				mappers := &ErrorMappers{}
+				mappers.Is(sql.ErrNoRows, func(err error) *Error { return shared })
+				mappers.Register(func(err error) ErrorType {
+					if !errors.Is(err, context.Canceled) {
+						return nil
+					}
+					return list
+				})
+
+				first := fmt.Errorf("first: %w", sql.ErrNoRows)
+				second := fmt.Errorf("second: %w", sql.ErrNoRows)
+				So(errors.Is(mappers.Convert(first), first), ShouldBeTrue)
+				So(errors.Is(mappers.Convert(second), second), ShouldBeTrue)
+				So(errors.Is(mappers.Convert(second), first), ShouldBeFalse)
+				So(shared.Cause, ShouldBeNil)
+
+				So(errors.Is(mappers.Convert(context.Canceled), context.Canceled), ShouldBeTrue)
+				So(list[0].Cause, ShouldBeNil)
+			})
+
+			Convey("should unwrap jsh errors", func() {
+				notFound := NotFound("tests", "1")
+				So(mappers.Convert(fmt.Errorf("wrapped: %w", notFound)), ShouldEqual, notFound)
+			})
+
+			Convey("should keep every error of a list", func() {
+				list := ErrorList{NotFound("tests", "1"), InputError("Invalid", "name")}
+				So(mappers.Convert(list), ShouldResemble, list)
+				So(ConvertError(fmt.Errorf("wrapped: %w", list)), ShouldResemble, list)
+			})
+
+			Convey("should fall back to an ISE", func() {
+				cause := errors.New("disk full")
+				err := mappers.Convert(cause)
+				So(err.StatusCode(), ShouldEqual, http.StatusInternalServerError)
+				So(errors.Is(err, cause), ShouldBeTrue)
+				So(mappers.Convert(nil), ShouldBeNil)
+			})
+		})
+
+		Convey("->Send()", func() {
+			writer := httptest.NewRecorder()
+			req := &http.Request{Method: "GET"}
+
+			sendErr := Send(writer, req, mappers.Convert(errors.New("secret connection string")))
+			So(sendErr, ShouldBeNil)
+			So(writer.Code, ShouldEqual, http.StatusInternalServerError)
+			So(writer.Body.String(), ShouldNotContainSubstring, "secret")
+		})
+	})
+}
@@ -0,0 +1,365 @@
+package jsh
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+)
+
+/*
+ObjectMarshaler is implemented by models that marshal themselves to a resource object,
+replacing the reflection-based marshaling of MarshalObject.
+*/
+type ObjectMarshaler interface {
+	MarshalObject() (*Object, *Error)
+}
+
+/*
+ObjectCustomizer is implemented by models that customize the resource object produced
+by the reflection-based marshaling, for instance to add links or meta.
+*/
+type ObjectCustomizer interface {
+	CustomizeObject(object *Object) *Error
+}
+
+/*
+MarshalObject converts a model to a resource object using its struct tags, and returns
+the resource objects of its related models to include in a compound document.
+
+The model must be a struct, or a pointer to a struct, with a primary field tagged
+`jsh:"primary,<type>"` holding the ID of the resource. Relationship fields are tagged
+"one" or "many" as for ProcessCreate, and the other fields are marshaled to the
+attributes with encoding/json:
+
+	type Article struct {
+		ID       int                  `json:"-"     jsh:"primary,articles"`
+		Title    string               `json:"title" jsh:"create,update"`
+		Author   *User                `json:"-"     jsh:"one,create"`
+		Comments []*Comment           `json:"-"     jsh:"many"`
+		Tags     map[string]*IDObject `json:"-"     jsh:"many,update"`
+	}
+
+	object, included, err := jsh.MarshalObject(article)
+
+To-one relationship fields are either a *IDObject or a (pointer to a) model. To-many
+relationship fields are maps of *IDObject as for ProcessCreate, or slices of *IDObject
+or of models. Related models are marshaled recursively to the included resources,
+each of them once. Self and relationship links are added to every resource object.
+*/
+func MarshalObject(model interface{}) (*Object, []*Object, *Error) {
+	m := newStructMarshaler()
+	rv := reflect.ValueOf(model)
+	if err := m.markPrimary(rv); err != nil {
+		return nil, nil, err
+	}
+	object, err := m.object(rv)
+	if err != nil {
+		return nil, nil, err
+	}
+	return object, m.included, nil
+}
+
+// MarshalList converts a slice of models to a list of resource objects, see MarshalObject.
+// Related models that are also part of the list are not included.
+func MarshalList(models interface{}) (List, []*Object, *Error) {
+	rv := reflect.ValueOf(models)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return nil, nil, ISE(fmt.Sprintf("MarshalList expects a slice of models, got %T", models))
+	}
+
+	m := newStructMarshaler()
+	for i := 0; i < rv.Len(); i++ {
+		if err := m.markPrimary(rv.Index(i)); err != nil {
+			return nil, nil, err
+		}
+	}
+	list := List{}
+	for i := 0; i < rv.Len(); i++ {
+		object, err := m.object(rv.Index(i))
+		if err != nil {
+			return nil, nil, err
+		}
+		list = append(list, object)
+	}
+	return list, m.included, nil
+}
+
+// structMarshaler marshals a graph of models, visiting each resource once.
+type structMarshaler struct {
+	included []*Object
+	visited  map[resourceKey]bool
+}
+
+func newStructMarshaler() *structMarshaler {
+	return &structMarshaler{visited: map[resourceKey]bool{}}
+}
+
+// markPrimary marks the model as visited, so that it is not included.
+func (m *structMarshaler) markPrimary(rv reflect.Value) *Error {
+	linkage, err := m.identify(rv)
+	if err != nil {
+		return err
+	}
+	if linkage != nil {
+		m.visited[linkage.key()] = true
+	}
+	return nil
+}
+
+// object marshals the model to a resource object.
+func (m *structMarshaler) object(rv reflect.Value) (*Object, *Error) {
+	rv = unwrapInterface(rv)
+	if marshaler, ok := asInterface(rv).(ObjectMarshaler); ok {
+		return marshaler.MarshalObject()
+	}
+
+	sv := reflect.Indirect(rv)
+	if sv.Kind() != reflect.Struct {
+		return nil, ISE(fmt.Sprintf("Cannot marshal %v to a resource object, must be a struct", rv.Type()))
+	}
+	resourceType, id, err := primaryField(sv)
+	if err != nil {
+		return nil, err
+	}
+	object, err := NewObject(id, resourceType, nil)
+	if err != nil {
+		return nil, err
+	}
+	m.visited[object.key()] = true
+
+	// Marshal the whole model with encoding/json, then remove non-attribute fields
+	raw, jsonErr := json.Marshal(sv.Interface())
+	if jsonErr != nil {
+		return nil, ISE(fmt.Sprintf("Error marshaling attrs of type '%s': %s", resourceType, jsonErr))
+	}
+	attributes := map[string]json.RawMessage{}
+	if jsonErr := json.Unmarshal(raw, &attributes); jsonErr != nil {
+		return nil, ISE(fmt.Sprintf("Error marshaling attrs of type '%s': %s", resourceType, jsonErr))
+	}
+
+	for _, field := range modelFields(sv) {
+		f := field.StructField
+		rawTags := f.Tag.Get(tagNameJSH)
+		tags := decodeFieldTags(rawTags)
+		_, one := tags[tagToOne]
+		_, many := tags[tagToMany]
+		if _, primary := decodePrimaryTag(rawTags); !primary && !one && !many {
+			continue
+		}
+		// Fields ignored by encoding/json are not part of the attributes
+		if name := decodeJSONTag(f); name != tagIgnore {
+			delete(attributes, name)
+		}
+		if !one && !many {
+			continue
+		}
+
+		name := toLowerFirstRune(f.Name)
+		linkage, err := m.relationship(name, many, field.value)
+		if err != nil {
+			return nil, err
+		}
+		object.Relationships[name] = &Relationship{
+			Links: NewRelationshipLinks(id, resourceType, name),
+			Data:  linkage,
+		}
+	}
+
+	if len(attributes) > 0 {
+		if err := object.Marshal(attributes); err != nil {
+			return nil, err
+		}
+	}
+	object.AddSelfLink()
+
+	if customizer, ok := asInterface(rv).(ObjectCustomizer); ok {
+		if err := customizer.CustomizeObject(object); err != nil {
+			return nil, err
+		}
+	}
+	return object, nil
+}
+
+// relationship returns the resource linkage of the relationship field, and marshals
+// its related models to the included resources.
+func (m *structMarshaler) relationship(name string, many bool, fv reflect.Value) (IDList, *Error) {
+	if !many {
+		linkage, err := m.related(fv)
+		if err != nil || linkage == nil {
+			return nil, err
+		}
+		return IDList{linkage}, nil
+	}
+
+	linkage := IDList{}
+	switch fv.Kind() {
+	case reflect.Map:
+		for _, key := range fv.MapKeys() {
+			related, err := m.related(fv.MapIndex(key))
+			if err != nil {
+				return nil, err
+			}
+			if related != nil {
+				linkage = append(linkage, related)
+			}
+		}
+		// Map iteration order is random, sort by ID for a stable output
+		sort.Stable(linkage)
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < fv.Len(); i++ {
+			related, err := m.related(fv.Index(i))
+			if err != nil {
+				return nil, err
+			}
+			if related != nil {
+				linkage = append(linkage, related)
+			}
+		}
+	default:
+		return nil, ISE(fmt.Sprintf("Invalid field type %v for to-many relationship '%s'", fv.Type(), name))
+	}
+	return linkage, nil
+}
+
+// related returns the resource linkage of a related model or resource identifier.
+// Models that were not visited yet are marshaled to the included resources.
+func (m *structMarshaler) related(fv reflect.Value) (*IDObject, *Error) {
+	fv = unwrapInterface(fv)
+	linkage, err := m.identify(fv)
+	if err != nil || linkage == nil {
+		return nil, err
+	}
+	if m.visited[linkage.key()] || isIDObject(fv) {
+		return linkage, nil
+	}
+
+	object, err := m.object(fv)
+	if err != nil {
+		return nil, err
+	}
+	m.included = append(m.included, object)
+	return linkage, nil
+}
+
+// identify returns the resource identifier of a model or resource identifier, or nil
+// if the value is nil.
+func (m *structMarshaler) identify(rv reflect.Value) (*IDObject, *Error) {
+	rv = unwrapInterface(rv)
+	if !rv.IsValid() || (rv.Kind() == reflect.Ptr || rv.Kind() == reflect.Interface) && rv.IsNil() {
+		return nil, nil
+	}
+	if isIDObject(rv) {
+		linkage := reflect.Indirect(rv).Interface().(IDObject)
+		return &linkage, nil
+	}
+	if marshaler, ok := asInterface(rv).(ObjectMarshaler); ok {
+		object, err := marshaler.MarshalObject()
+		if err != nil {
+			return nil, err
+		}
+		return object.ToIDObject(), nil
+	}
+
+	sv := reflect.Indirect(rv)
+	if sv.Kind() != reflect.Struct {
+		return nil, ISE(fmt.Sprintf("Cannot marshal %v to a resource object, must be a struct", rv.Type()))
+	}
+	resourceType, id, err := primaryField(sv)
+	if err != nil {
+		return nil, err
+	}
+	return NewIDObject(resourceType, id), nil
+}
+
+// primaryField returns the resource type and the ID of the model from its primary field.
+func primaryField(sv reflect.Value) (string, string, *Error) {
+	for _, field := range modelFields(sv) {
+		resourceType, ok := decodePrimaryTag(field.Tag.Get(tagNameJSH))
+		if !ok {
+			continue
+		}
+		if resourceType == "" {
+			return "", "", ISE(fmt.Sprintf("Missing resource type in primary field of %v", sv.Type()))
+		}
+		return resourceType, formatID(field.value), nil
+	}
+	return "", "", ISE(fmt.Sprintf("No primary field tagged `jsh:\"primary,<type>\"` in %v", sv.Type()))
+}
+
+// modelField is an exported field of a model and its value.
+type modelField struct {
+	reflect.StructField
+	value reflect.Value
+}
+
+// modelFields returns the exported fields of the model, including the fields of its
+// embedded structs as encoding/json does.
+func modelFields(sv reflect.Value) []modelField {
+	var fields []modelField
+	st := sv.Type()
+	for i := 0; i < st.NumField(); i++ {
+		f := st.Field(i)
+		fv := sv.Field(i)
+		if f.Anonymous && f.Tag.Get(tagNameJSH) == "" {
+			if fv.Kind() == reflect.Ptr && !fv.IsNil() {
+				fv = fv.Elem()
+			}
+			if fv.Kind() == reflect.Struct {
+				fields = append(fields, modelFields(fv)...)
+				continue
+			}
+		}
+		if f.PkgPath != "" {
+			continue
+		}
+		fields = append(fields, modelField{f, fv})
+	}
+	return fields
+}
+
+// formatID formats the value of a primary field to a resource ID.
+func formatID(v reflect.Value) string {
+	switch v.Kind() {
+	case reflect.String:
+		return v.String()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if v.Int() == 0 {
+			return ""
+		}
+		return strconv.FormatInt(v.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if v.Uint() == 0 {
+			return ""
+		}
+		return strconv.FormatUint(v.Uint(), 10)
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return ""
+		}
+		return formatID(v.Elem())
+	}
+	return fmt.Sprintf("%v", v.Interface())
+}
+
+// isIDObject returns true if the value is an IDObject or a pointer to one.
+func isIDObject(rv reflect.Value) bool {
+	return reflect.Indirect(rv).Type() == reflect.TypeOf(IDObject{})
+}
+
+// unwrapInterface returns the value held by a non-nil interface value.
+func unwrapInterface(rv reflect.Value) reflect.Value {
+	for rv.Kind() == reflect.Interface && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	return rv
+}
+
+// asInterface returns the value as an interface, or nil if it cannot be used as one.
+func asInterface(rv reflect.Value) interface{} {
+	if !rv.IsValid() || !rv.CanInterface() {
+		return nil
+	}
+	return rv.Interface()
+}
@@ -0,0 +1,159 @@
+package jsh
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+type testAuthor struct {
+	ID       string         `json:"-"    jsh:"primary,people"`
+	Name     string         `json:"name" jsh:"create"`
+	Articles []*testArticle `json:"-"    jsh:"many"`
+}
+
+type testArticle struct {
+	ID     int                  `json:"-"               jsh:"primary,articles"`
+	Title  string               `json:"title"           jsh:"create,update"`
+	Draft  bool                 `json:"draft,omitempty" jsh:"update"`
+	Author *testAuthor          `json:"-"               jsh:"one,create"`
+	Editor *IDObject            `json:"-"               jsh:"one"`
+	Tags   map[string]*IDObject `json:"-"               jsh:"many,update"`
+}
+
+type testCustomArticle struct {
+	testArticle
+}
+
+func (a *testCustomArticle) CustomizeObject(object *Object) *Error {
+	object.Meta = map[string]interface{}{"custom": true}
+	return nil
+}
+
+type testUntaggedAuthor struct {
+	ID   int    `jsh:"primary,people"`
+	Name string `json:"name"`
+}
+
+type testUntaggedArticle struct {
+	ID     string              `jsh:"primary,articles"`
+	Title  string              `json:"title"`
+	Author *testUntaggedAuthor `jsh:"one"`
+	Secret string              `json:"-"`
+}
+
+type testMarshaler struct{}
+
+func (testMarshaler) MarshalObject() (*Object, *Error) {
+	return NewObject("42", "custom", map[string]string{"from": "marshaler"})
+}
+
+func TestMarshal(t *testing.T) {
+
+	Convey("Marshal Tests", t, func() {
+
+		author := &testAuthor{ID: "9", Name: "Jon"}
+		article := &testArticle{
+			ID:     1,
+			Title:  "JSON API",
+			Author: author,
+			Tags: map[string]*IDObject{
+				"2": NewIDObject("tags", "2"),
+				"1": NewIDObject("tags", "1"),
+			},
+		}
+		author.Articles = []*testArticle{article, {ID: 2, Title: "Go"}}
+
+		Convey("->MarshalObject()", func() {
+
+			Convey("should marshal attributes, relationships and links", func() {
+				object, included, err := MarshalObject(article)
+				So(err, ShouldBeNil)
+				So(object.ID, ShouldEqual, "1")
+				So(object.Type, ShouldEqual, "articles")
+				So(string(object.Attributes), ShouldEqual, `{"title":"JSON API"}`)
+				So(object.Links["self"].HREF, ShouldEqual, "/articles/1")
+
+				So(object.Relationships, ShouldHaveLength, 3)
+				So(object.Relationships["author"].Data, ShouldResemble, IDList{NewIDObject("people", "9")})
+				So(object.Relationships["author"].Links.Related.HREF, ShouldEqual, "/articles/1/author")
+				So(object.Relationships["editor"].Data, ShouldBeNil)
+				So(object.Relationships["tags"].Data, ShouldResemble, IDList{
+					NewIDObject("tags", "1"),
+					NewIDObject("tags", "2"),
+				})
+
+				So(included, ShouldHaveLength, 2)
+				So(included[0].key(), ShouldResemble, resourceKey{Type: "articles", ID: "2"})
+				So(included[1].key(), ShouldResemble, resourceKey{Type: "people", ID: "9"})
+				So(included[1].Relationships["articles"].Data, ShouldResemble, IDList{
+					NewIDObject("articles", "1"),
+					NewIDObject("articles", "2"),
+				})
+			})
+
+			Convey("should build a valid compound document", func() {
+				object, included, err := MarshalObject(article)
+				So(err, ShouldBeNil)
+				So(object.Validate(&http.Request{Method: "GET"}, true), ShouldBeNil)
+
+				doc, err := BuildCompound(object, included...)
+				So(err, ShouldBeNil)
+				So(doc.Validate(&http.Request{Method: "GET"}, true), ShouldBeNil)
+			})
+
+			Convey("should use the model interfaces", func() {
+				object, _, err := MarshalObject(&testCustomArticle{testArticle{ID: 3}})
+				So(err, ShouldBeNil)
+				So(object.Meta, ShouldResemble, map[string]interface{}{"custom": true})
+				So(object.Relationships, ShouldContainKey, "author")
+
+				object, _, err = MarshalObject(testMarshaler{})
+				So(err, ShouldBeNil)
+				So(object.ID, ShouldEqual, "42")
+			})
+
+			Convey("should remove untagged primary and relationship fields from the attributes", func() {
+				model := &testUntaggedArticle{ID: "1", Title: "t", Author: &testUntaggedAuthor{ID: 2, Name: "n"}, Secret: "s"}
+				object, included, err := MarshalObject(model)
+				So(err, ShouldBeNil)
+				So(string(object.Attributes), ShouldEqual, `{"title":"t"}`)
+				So(object.Relationships["author"].Data, ShouldResemble, IDList{NewIDObject("people", "2")})
+				So(included, ShouldHaveLength, 1)
+				So(string(included[0].Attributes), ShouldEqual, `{"name":"n"}`)
+			})
+
+			Convey("should reject models without primary field", func() {
+				_, _, err := MarshalObject(&struct{ Name string }{"Jon"})
+				So(err, ShouldNotBeNil)
+				So(err.Status, ShouldEqual, http.StatusInternalServerError)
+
+				_, _, err = MarshalObject("not a struct")
+				So(err, ShouldNotBeNil)
+			})
+		})
+
+		Convey("->MarshalList()", func() {
+
+			Convey("should not include models of the list", func() {
+				list, included, err := MarshalList(author.Articles)
+				So(err, ShouldBeNil)
+				So(list, ShouldHaveLength, 2)
+				So(list[1].Relationships["author"].Data, ShouldBeNil)
+				So(included, ShouldHaveLength, 1)
+				So(included[0].ID, ShouldEqual, "9")
+
+				raw, jsonErr := json.Marshal(list)
+				So(jsonErr, ShouldBeNil)
+				So(string(raw), ShouldNotContainSubstring, `"id":"0"`)
+			})
+
+			Convey("should reject other values", func() {
+				_, _, err := MarshalList(article)
+				So(err, ShouldNotBeNil)
+			})
+		})
+	})
+}
@@ -0,0 +1,297 @@
+package jsh
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+const (
+	// acceptQuality is the Accept header parameter holding the quality value of a media range.
+	// It is not a media type parameter and is ignored during negotiation.
+	acceptQuality = "q"
+	// mediaTypeExt is the JSON API media type parameter listing the applied extensions
+	mediaTypeExt = "ext"
+	// mediaTypeProfile is the JSON API media type parameter listing the applied profiles
+	mediaTypeProfile = "profile"
+)
+
+var (
+	// supportedExtensions contains the URI of every extension supported by the server
+	supportedExtensions = newURISet()
+	// supportedProfiles contains the URI of every profile supported by the server
+	supportedProfiles = newURISet()
+)
+
+/*
+RegisterExtension declares that the server supports the JSON API extension identified
+by the given URI: http://jsonapi.org/format/1.1/#extensions
+
+Requests using unsupported extensions are rejected during content negotiation,
+while supported ones requested by the client are applied to responses by Send.
+It is safe to register extensions while requests are being served.
+*/
+func RegisterExtension(uri string) {
+	supportedExtensions.add(uri)
+}
+
+/*
+RegisterProfile declares that the server supports the JSON API profile identified
+by the given URI: http://jsonapi.org/format/1.1/#profiles
+
+Unsupported profiles requested by a client are ignored, while supported ones are
+applied to responses by Send. It is safe to register profiles while requests are
+being served.
+*/
+func RegisterProfile(uri string) {
+	supportedProfiles.add(uri)
+}
+
+// uriSet is a set of URIs safe for concurrent use, read by every negotiation.
+type uriSet struct {
+	mutex sync.RWMutex
+	uris  map[string]bool
+}
+
+func newURISet() *uriSet {
+	return &uriSet{uris: map[string]bool{}}
+}
+
+func (s *uriSet) add(uri string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.uris[uri] = true
+}
+
+func (s *uriSet) remove(uri string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	delete(s.uris, uri)
+}
+
+func (s *uriSet) has(uri string) bool {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.uris[uri]
+}
+
+// MediaType is a media type along with its parameters, as found in the Content-Type
+// and Accept headers.
+type MediaType struct {
+	// Type is the lower-case media type, e.g. "application/vnd.api+json"
+	Type string
+	// Params contains the media type parameters by lower-case name
+	Params map[string]string
+}
+
+// ParseMediaType parses a media type such as `application/vnd.api+json; ext="..."`.
+func ParseMediaType(s string) (*MediaType, error) {
+	mediaType, params, err := mime.ParseMediaType(s)
+	if err != nil {
+		return nil, err
+	}
+	return &MediaType{Type: mediaType, Params: params}, nil
+}
+
+// IsJSONAPI returns true if the media type is the JSON API media type, regardless of its parameters.
+func (m *MediaType) IsJSONAPI() bool {
+	return m.Type == ContentType
+}
+
+// Extensions returns the extension URIs of the ext media type parameter.
+func (m *MediaType) Extensions() []string {
+	return strings.Fields(m.Params[mediaTypeExt])
+}
+
+// Profiles returns the profile URIs of the profile media type parameter.
+func (m *MediaType) Profiles() []string {
+	return strings.Fields(m.Params[mediaTypeProfile])
+}
+
+// String formats the media type with its parameters as expected in HTTP headers.
+func (m *MediaType) String() string {
+	return mime.FormatMediaType(m.Type, m.Params)
+}
+
+// addExtension adds the extension to the ext media type parameter if not already present.
+func (m *MediaType) addExtension(uri string) {
+	ext := m.Extensions()
+	if containsString(ext, uri) {
+		return
+	}
+	m.Params[mediaTypeExt] = strings.Join(append(ext, uri), " ")
+}
+
+// validate checks that the JSON API media type only has ext and profile parameters,
+// and that every extension is supported.
+func (m *MediaType) validate() error {
+	for name := range m.Params {
+		if name != mediaTypeExt && name != mediaTypeProfile {
+			return fmt.Errorf("Media type parameter '%s' is not allowed", name)
+		}
+	}
+	for _, ext := range m.Extensions() {
+		if !supportedExtensions.has(ext) {
+			return fmt.Errorf("Extension '%s' is not supported", ext)
+		}
+	}
+	return nil
+}
+
+/*
+NegotiatedMediaType returns the media type of the response to the request: the JSON API
+media type with the supported extensions and profiles requested by the client.
+
+The extensions and profiles are those of the first acceptable instance of the JSON API
+media type in the Accept header. If there is none, those of the request Content-Type
+are used instead.
+*/
+func NegotiatedMediaType(r *http.Request) *MediaType {
+	requested := firstAcceptable(acceptedJSONAPI(r.Header))
+	if requested == nil {
+		mediaType, err := ParseMediaType(r.Header.Get("Content-Type"))
+		if err == nil && mediaType.IsJSONAPI() && mediaType.validate() == nil {
+			requested = mediaType
+		}
+	}
+
+	result := &MediaType{Type: ContentType, Params: map[string]string{}}
+	if requested == nil {
+		return result
+	}
+	if ext := requested.Extensions(); len(ext) > 0 {
+		result.Params[mediaTypeExt] = strings.Join(ext, " ")
+	}
+	var profiles []string
+	for _, profile := range requested.Profiles() {
+		if supportedProfiles.has(profile) {
+			profiles = append(profiles, profile)
+		}
+	}
+	if len(profiles) > 0 {
+		result.Params[mediaTypeProfile] = strings.Join(profiles, " ")
+	}
+	return result
+}
+
+/*
+Negotiate performs the JSON API content negotiation of the request:
+http://jsonapi.org/format/#content-negotiation-servers
+
+It returns a 415 Unsupported Media Type error if the request has a body and its
+Content-Type is not the JSON API media type, has parameters other than ext and profile,
+or uses an unsupported extension (see RegisterExtension).
+
+It returns a 406 Not Acceptable error if the Accept header contains the JSON API media
+type and none of its instances is acceptable: every instance has parameters other than
+ext and profile, or uses an unsupported extension.
+*/
+func Negotiate(r *http.Request) *Error {
+	if r.Header.Get("Content-Type") != "" || r.ContentLength > 0 {
+		if err := validateContentType(r.Header); err != nil {
+			return err
+		}
+	}
+	return validateAccept(r.Header)
+}
+
+/*
+NegotiationHandler is an http.Handler middleware that performs the JSON API content
+negotiation (see Negotiate) before calling the next handler. The negotiation error,
+if any, is sent as a JSON API error response.
+
+	http.Handle("/articles", jsh.NegotiationHandler(articlesHandler))
+*/
+func NegotiationHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := Negotiate(r); err != nil {
+			Send(w, r, err)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// validateContentType returns a 415 error if the Content-Type header is not the JSON API
+// media type or if its media type parameters are not acceptable.
+func validateContentType(headers http.Header) *Error {
+	reqContentType := headers.Get("Content-Type")
+	mediaType, err := ParseMediaType(reqContentType)
+	if err != nil || !mediaType.IsJSONAPI() {
+		return UnsupportedMediaTypeError(fmt.Sprintf(
+			"Expected Content-Type header to be %s, got: %s",
+			ContentType,
+			reqContentType,
+		))
+	}
+	if err := mediaType.validate(); err != nil {
+		return UnsupportedMediaTypeError(fmt.Sprintf("Invalid Content-Type header: %s", err))
+	}
+	return nil
+}
+
+// validateAccept returns a 406 error if the Accept header contains instances of the JSON API
+// media type and none of them is acceptable.
+func validateAccept(headers http.Header) *Error {
+	instances := acceptedJSONAPI(headers)
+	if len(instances) == 0 || firstAcceptable(instances) != nil {
+		return nil
+	}
+	return NotAcceptableError(fmt.Sprintf(
+		"No instance of %s in Accept header is acceptable: %s",
+		ContentType,
+		instances[0].validate(),
+	))
+}
+
+// firstAcceptable returns the first acceptable media type of the list, or nil if there is none.
+func firstAcceptable(instances []*MediaType) *MediaType {
+	for _, mediaType := range instances {
+		if mediaType.validate() == nil {
+			return mediaType
+		}
+	}
+	return nil
+}
+
+// acceptedJSONAPI returns the instances of the JSON API media type found in the Accept headers,
+// without their quality parameter. Malformed media ranges are ignored.
+func acceptedJSONAPI(headers http.Header) []*MediaType {
+	var instances []*MediaType
+	for _, header := range headers[http.CanonicalHeaderKey("Accept")] {
+		for _, mediaRange := range splitHeader(header) {
+			mediaType, err := ParseMediaType(mediaRange)
+			if err != nil || !mediaType.IsJSONAPI() {
+				continue
+			}
+			delete(mediaType.Params, acceptQuality)
+			instances = append(instances, mediaType)
+		}
+	}
+	return instances
+}
+
+// splitHeader splits a comma separated header value, ignoring commas within quoted strings.
+func splitHeader(header string) []string {
+	var parts []string
+	quoted := false
+	start := 0
+	for i := 0; i < len(header); i++ {
+		switch header[i] {
+		case '\\':
+			if quoted {
+				i++
+			}
+		case '"':
+			quoted = !quoted
+		case ',':
+			if !quoted {
+				parts = append(parts, strings.TrimSpace(header[start:i]))
+				start = i + 1
+			}
+		}
+	}
+	return append(parts, strings.TrimSpace(header[start:]))
+}
@@ -0,0 +1,195 @@
+package jsh
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestNegotiation(t *testing.T) {
+
+	Convey("Negotiation Tests", t, func() {
+
+		req, err := http.NewRequest("GET", "/articles", nil)
+		So(err, ShouldBeNil)
+
+		Convey("->Negotiate()", func() {
+
+			Convey("should accept a request without body nor Accept header", func() {
+				So(Negotiate(req), ShouldBeNil)
+			})
+
+			Convey("Content-Type", func() {
+				req.Method = "POST"
+
+				Convey("should accept the JSON API media type", func() {
+					req.Header.Set("Content-Type", ContentType)
+					So(Negotiate(req), ShouldBeNil)
+				})
+
+				Convey("should reject media type parameters with a 415", func() {
+					req.Header.Set("Content-Type", ContentType+"; charset=utf-8")
+					err := Negotiate(req)
+					So(err, ShouldNotBeNil)
+					So(err.Status, ShouldEqual, http.StatusUnsupportedMediaType)
+				})
+
+				Convey("should reject another media type with a 415", func() {
+					req.Header.Set("Content-Type", "application/json")
+					err := Negotiate(req)
+					So(err, ShouldNotBeNil)
+					So(err.Status, ShouldEqual, http.StatusUnsupportedMediaType)
+				})
+
+				Convey("should reject a body without Content-Type with a 415", func() {
+					req.ContentLength = 10
+					err := Negotiate(req)
+					So(err, ShouldNotBeNil)
+					So(err.Status, ShouldEqual, http.StatusUnsupportedMediaType)
+				})
+			})
+
+			Convey("Accept", func() {
+
+				Convey("should accept at least one instance without parameters", func() {
+					req.Header.Set("Accept", ContentType+`; charset="a,b", `+ContentType+";q=0.5, text/html")
+					So(Negotiate(req), ShouldBeNil)
+				})
+
+				Convey("should accept other media types", func() {
+					req.Header.Set("Accept", "application/json, */*")
+					So(Negotiate(req), ShouldBeNil)
+				})
+
+				Convey("should reject when every instance has parameters with a 406", func() {
+					req.Header.Set("Accept", ContentType+"; charset=utf-8, text/html")
+					req.Header.Add("Accept", ContentType+"; version=2")
+					err := Negotiate(req)
+					So(err, ShouldNotBeNil)
+					So(err.Status, ShouldEqual, http.StatusNotAcceptable)
+				})
+			})
+		})
+
+		Convey("->NegotiationHandler()", func() {
+			called := false
+			handler := NegotiationHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				called = true
+			}))
+
+			Convey("should call the next handler", func() {
+				writer := httptest.NewRecorder()
+				handler.ServeHTTP(writer, req)
+				So(called, ShouldBeTrue)
+			})
+
+			Convey("should respond with a JSON API error", func() {
+				req.Header.Set("Accept", ContentType+"; charset=utf-8")
+				writer := httptest.NewRecorder()
+				handler.ServeHTTP(writer, req)
+				So(called, ShouldBeFalse)
+				So(writer.Code, ShouldEqual, http.StatusNotAcceptable)
+				So(writer.Header().Get("Content-Type"), ShouldEqual, ContentType)
+				So(strings.Contains(writer.Body.String(), "errors"), ShouldBeTrue)
+			})
+		})
+
+		Convey("Extensions and profiles", func() {
+			testExt := "https://example.com/ext/test"
+			testProfile := "https://example.com/profiles/test"
+			RegisterExtension(testExt)
+			RegisterProfile(testProfile)
+			Reset(func() {
+				supportedExtensions.remove(testExt)
+				supportedProfiles.remove(testProfile)
+			})
+
+			Convey("->Negotiate()", func() {
+
+				Convey("should accept supported extensions and any profile", func() {
+					req.Method = "POST"
+					req.Header.Set("Content-Type", ContentType+`; ext="`+testExt+`"; profile="https://example.com/other"`)
+					req.Header.Set("Accept", ContentType+`; ext="`+testExt+`"`)
+					So(Negotiate(req), ShouldBeNil)
+				})
+
+				Convey("should allow registrations while negotiating", func() {
+					req.Header.Set("Accept", ContentType+`; ext="`+testExt+`"; profile="`+testProfile+`"`)
+					done := make(chan bool)
+					go func() {
+						for i := 0; i < 100; i++ {
+							uri := fmt.Sprintf("https://example.com/ext/concurrent/%d", i)
+							RegisterExtension(uri)
+							RegisterProfile(uri)
+							supportedExtensions.remove(uri)
+							supportedProfiles.remove(uri)
+						}
+						close(done)
+					}()
+					for i := 0; i < 100; i++ {
+						So(Negotiate(req), ShouldBeNil)
+						So(NegotiatedMediaType(req).Profiles(), ShouldResemble, []string{testProfile})
+					}
+					<-done
+				})
+
+				Convey("should reject an unsupported extension in Content-Type with a 415", func() {
+					req.Method = "POST"
+					req.Header.Set("Content-Type", ContentType+`; ext="`+testExt+` https://example.com/ext/other"`)
+					err := Negotiate(req)
+					So(err, ShouldNotBeNil)
+					So(err.Status, ShouldEqual, http.StatusUnsupportedMediaType)
+				})
+
+				Convey("should reject unsupported extensions in every Accept instance with a 406", func() {
+					req.Header.Set("Accept", ContentType+`; ext="https://example.com/ext/other", `+ContentType+`; charset=utf-8`)
+					err := Negotiate(req)
+					So(err, ShouldNotBeNil)
+					So(err.Status, ShouldEqual, http.StatusNotAcceptable)
+				})
+			})
+
+			Convey("->NegotiatedMediaType()", func() {
+
+				Convey("should apply the first acceptable instance", func() {
+					req.Header.Set("Accept", ContentType+`; ext="https://example.com/ext/other", `+
+						ContentType+`; ext="`+testExt+`"; profile="`+testProfile+` https://example.com/other"`)
+
+					mediaType := NegotiatedMediaType(req)
+					So(mediaType.Extensions(), ShouldResemble, []string{testExt})
+					So(mediaType.Profiles(), ShouldResemble, []string{testProfile})
+					So(mediaType.String(), ShouldEqual, ContentType+`; ext="`+testExt+`"; profile="`+testProfile+`"`)
+				})
+
+				Convey("should default to the JSON API media type", func() {
+					So(NegotiatedMediaType(req).String(), ShouldEqual, ContentType)
+				})
+			})
+
+			Convey("->Send()", func() {
+
+				Convey("should echo the applied extensions and profiles", func() {
+					req.Header.Set("Accept", ContentType+`; ext="`+testExt+`"; profile="`+testProfile+`"`)
+					writer := httptest.NewRecorder()
+
+					err := Send(writer, req, Ok())
+					So(err, ShouldBeNil)
+					So(writer.Header().Get("Content-Type"), ShouldEqual, ContentType+`; ext="`+testExt+`"; profile="`+testProfile+`"`)
+
+					object, objErr := NewObject("1", "articles", nil)
+					So(objErr, ShouldBeNil)
+					writer = httptest.NewRecorder()
+
+					err = Send(writer, req, object)
+					So(err, ShouldBeNil)
+					So(writer.Body.String(), ShouldContainSubstring, `"ext":[`)
+					So(writer.Body.String(), ShouldContainSubstring, `"profile":[`)
+				})
+			})
+		})
+	})
+}
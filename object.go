@@ -1,6 +1,7 @@
 package jsh
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -12,7 +13,8 @@ import (
 // Object represents the default JSON spec for objects
 type Object struct {
 	Type          string                   `json:"type" valid:"required"`
-	ID            string                   `json:"id"`
+	ID            string                   `json:"id,omitempty"`
+	LID           string                   `json:"lid,omitempty"`
 	Attributes    json.RawMessage          `json:"attributes,omitempty"`
 	Links         map[string]*Link         `json:"links,omitempty"`
 	Relationships map[string]*Relationship `json:"relationships,omitempty"`
@@ -85,13 +87,29 @@ func (o *Object) Unmarshal(resourceType string, target interface{}) ErrorList {
 /*
 Marshal allows you to load a modified payload back into an object to preserve
 all of the data it has.
+
+Attributes implementing AttributesMarshaler, such as the models generated with
+jshgen, are marshaled without reflection. Attributes are stored as compact JSON,
+documents are formatted when they are sent (see DefaultEncoder).
 */
 func (o *Object) Marshal(attributes interface{}) *Error {
 	if attributes == nil {
 		o.Attributes = json.RawMessage{}
 		return nil
 	}
-	raw, err := json.MarshalIndent(attributes, "", " ")
+	if marshaler, ok := attributes.(AttributesMarshaler); ok {
+		content, err := marshaler.MarshalAttributes()
+		if err != nil {
+			return ISE(fmt.Sprintf("Error marshaling attrs while creating a new JSON Object: %s", err))
+		}
+		var raw bytes.Buffer
+		if err := json.Compact(&raw, content); err != nil {
+			return ISE(fmt.Sprintf("Error marshaling attrs while creating a new JSON Object: %s", err))
+		}
+		o.Attributes = raw.Bytes()
+		return nil
+	}
+	raw, err := json.Marshal(attributes)
 	if err != nil {
 		return ISE(fmt.Sprintf("Error marshaling attrs while creating a new JSON Object: %s", err))
 	}
@@ -106,12 +124,11 @@ setting the Object's Status attribute to be used as the Response HTTP Code if on
 has not already been set.
 */
 func (o *Object) Validate(r *http.Request, response bool) *Error {
-	if o.ID == "" {
-		// don't error if the client is attempting to performing a POST request, in
-		// which case, ID shouldn't actually be set
-		if !response && r.Method != "POST" {
-			return SpecificationError("ID must be set for Object response")
-		}
+	// don't error if the client is attempting to performing a POST request, in
+	// which case, ID shouldn't actually be set. Other requests cannot replace the
+	// ID with a lid, which only stands for the ID of a resource created by the request.
+	if !response && r.Method != "POST" && o.ID == "" {
+		return SpecificationError("ID must be set for Object response")
 	}
 
 	if o.Type == "" {
@@ -204,7 +221,8 @@ The model must be a non-nil pointer to a struct.
 If valid, the model contains the valid request attributes after the call (even on validation error).
 Relationship fields, if any, are set to the IDObject values in object.Relationships.
 
-See the documentation of Validator.Validate for more detailed information.
+See the documentation of Validator.Validate for more detailed information. Models
+generated with jshgen (see cmd/jshgen) are validated without reflection.
 
 The string slice returned contains the names of the attributes and relationships
 that were unmarshaled to the model.
@@ -227,9 +245,26 @@ func (o *Object) ProcessUpdate(resourceType string, model interface{}) ([]string
 	return attrs, nil
 }
 
-// ToIDObject returns a resource identifier object created with the object type and ID.
+// ToIDObject returns a resource identifier object created with the object type, ID and LID.
 func (o *Object) ToIDObject() *IDObject {
-	return NewIDObject(o.Type, o.ID)
+	result := NewIDObject(o.Type, o.ID)
+	result.LID = o.LID
+	return result
+}
+
+// identified returns true if the object has an ID or a local identifier.
+func (o *Object) identified() bool {
+	return o.ID != "" || o.LID != ""
+}
+
+// identifiedFor returns true if the object of a request with the given method is
+// identified. A lid only stands for the ID of a resource created by a POST request,
+// other requests must provide the ID.
+func (o *Object) identifiedFor(method string) bool {
+	if method == "POST" {
+		return o.identified()
+	}
+	return o.ID != ""
 }
 
 // String prints a formatted string representation of the object
@@ -250,7 +285,11 @@ func (o *Object) process(action, resourceType string, model interface{}) ([]stri
 	if err != nil {
 		return nil, err
 	}
-	// Look for missing/forbidden attributes and relationships for action
+	// Look for missing/forbidden attributes and relationships for action,
+	// without reflection for the models generated with jshgen
+	if generated, ok := model.(ObjectValidator); ok {
+		return generated.ValidateObject(o, action)
+	}
 	return NewValidator(o, action).Validate(model)
 }
 
@@ -263,6 +302,9 @@ func validateRelationships(object *Object) ErrorList {
 				return RelationshipError(err.Err.Error(), name+"/data/"+strings.ToLower(err.Name))
 			}
 			errors = append(errors, validator(resourceID, adapter)...)
+			if !resourceID.identified() {
+				errors = append(errors, RelationshipError("Missing resource linkage id or lid", name+"/data/id"))
+			}
 		}
 	}
 	return errors
@@ -271,7 +313,14 @@ func validateRelationships(object *Object) ErrorList {
 // validateInput runs go-validator on each attribute of the struct and returns all errors.
 func validateInput(target interface{}) ErrorList {
 	adapter := func(err govalidator.Error) *Error {
-		return InputError(err.Err.Error(), toLowerFirstRune(err.Name))
+		attribute := toLowerFirstRune(err.Name)
+		inputErr := InputError(err.Err.Error(), attribute)
+		// custom messages of the model are already final, others can be localized
+		if !err.CustomErrorMessageExists && err.Validator != "" {
+			inputErr.DetailKey = MessageValidationPrefix + err.Validator
+			inputErr.DetailArgs = []interface{}{attribute}
+		}
+		return inputErr
 	}
 	return validator(target, adapter)
 }
@@ -302,6 +302,22 @@ func TestObject(t *testing.T) {
 					So(testConversion.Other, ShouldEqual, "bar")
 				})
 
+				Convey("Should use the field name of attributes without JSON tag", func() {
+					testConversion := struct {
+						Foo string `jsh:"create"`
+						Bar string `json:",omitempty" jsh:"create/required"`
+					}{}
+
+					f, err := testObject.ProcessCreate(testType, &testConversion)
+					So(err, ShouldNotBeNil)
+					So(err, ShouldHaveLength, 1)
+					So(err[0].StatusCode(), ShouldEqual, 422)
+					So(err[0].Source, ShouldNotBeNil)
+					So(err[0].Source.Pointer, ShouldEqual, "/data/attributes/bar")
+					So(f, ShouldBeNil)
+					So(testConversion.Foo, ShouldEqual, "bar")
+				})
+
 				Convey("Should ignore private attributes", func() {
 					testConversion := struct {
 						foo string `json:"foo" jsh:"create"`
@@ -845,7 +861,7 @@ func TestObject(t *testing.T) {
 				err := testObject.Marshal(attrs)
 				So(err, ShouldBeNil)
 
-				raw, jsonErr := json.MarshalIndent(attrs, "", " ")
+				raw, jsonErr := json.Marshal(attrs)
 				So(jsonErr, ShouldBeNil)
 				So(string(testObject.Attributes), ShouldEqual, string(raw))
 			})
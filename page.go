@@ -0,0 +1,378 @@
+package jsh
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+const (
+	paramPage        = "page"
+	pageNumber       = "number"
+	pageSize         = "size"
+	pageOffset       = "offset"
+	pageLimit        = "limit"
+	pageCursor       = "cursor"
+	firstPageNumber  = 1
+	unlimitedPageMax = 0
+	maxPageInt       = int(^uint(0) >> 1)
+)
+
+/*
+Page is the pagination state of a request, parsed from the page[...] query parameters:
+http://jsonapi.org/format/#fetching-pagination
+
+Only the fields of the pagination strategy used to parse the request are set.
+*/
+type Page struct {
+	// Number is the 1-based page number of page-based pagination
+	Number int
+	// Size is the page size of page-based and cursor-based pagination
+	Size int
+	// Offset is the 0-based item offset of offset-based pagination
+	Offset int
+	// Limit is the maximum number of items of offset-based pagination
+	Limit int
+	// Cursor is the opaque position of cursor-based pagination
+	Cursor string
+	// Total is the total number of items of the collection, 0 if unknown.
+	// It must be set for page-based and offset-based "next" and "last" links.
+	Total int
+	// PrevCursor and NextCursor are the cursors of the previous and next pages
+	// of cursor-based pagination, empty if there is no such page.
+	PrevCursor string
+	NextCursor string
+}
+
+// Bounds returns the offset of the first item of the page and the maximum number of items
+// in the page. A cursor-based page always starts at offset 0 from its cursor. An offset
+// that cannot be represented, which ParsePage rejects, saturates to the maximum int.
+func (p *Page) Bounds() (offset, limit int) {
+	switch {
+	case p.Number > 0:
+		if p.Size > 0 && p.Number-1 > maxPageInt/p.Size {
+			return maxPageInt, p.Size
+		}
+		return (p.Number - 1) * p.Size, p.Size
+	case p.Limit > 0:
+		return p.Offset, p.Limit
+	default:
+		return 0, p.Size
+	}
+}
+
+// Window returns the part of the list that belongs to the page.
+func (p *Page) Window(list List) List {
+	offset, limit := p.Bounds()
+	if offset < 0 {
+		offset = 0
+	}
+	if offset >= len(list) {
+		return List{}
+	}
+	end := len(list)
+	if limit > 0 && limit < end-offset {
+		end = offset + limit
+	}
+	return list[offset:end]
+}
+
+/*
+PageStrategy parses the page[...] query parameters of a request according to a
+pagination strategy. The params map contains each page parameter value by member
+name, e.g. "size" for "page[size]".
+
+LinkParams returns the page parameters of the first, previous, next and last pages
+relative to the given page, using the same representation. A nil map means that
+the corresponding link is omitted.
+*/
+type PageStrategy interface {
+	ParsePage(params map[string]string) (*Page, *Error)
+	LinkParams(page *Page) (first, prev, next, last map[string]string)
+}
+
+/*
+ParsePage parses the page[...] query parameters of the request with the given strategy.
+
+	strategy := jsh.NewNumberPagination(20, 100)
+
+	page, err := jsh.ParsePage(r, strategy)
+	if err != nil {
+		jsh.Send(w, r, err)
+		return
+	}
+
+	offset, limit := page.Bounds()
+*/
+func ParsePage(r *http.Request, strategy PageStrategy) (*Page, *Error) {
+	family, err := queryFamily(query(r), paramPage)
+	if err != nil {
+		return nil, err
+	}
+	params := map[string]string{}
+	for _, param := range family {
+		if len(param.Members) != 1 {
+			return nil, ParameterError(fmt.Sprintf("Malformed query parameter '%s'", param.Key), param.Key)
+		}
+		params[param.Members[0]] = param.Value
+	}
+	return strategy.ParsePage(params)
+}
+
+/*
+NewPaginationLinks returns the self and pagination links of a collection built from
+the request URL and the given page. Every query parameter that is not part of the
+page[...] family is kept intact.
+
+	page.Total = count
+	doc := jsh.Build(list)
+	doc.Links = jsh.NewPaginationLinks(r, strategy, page)
+*/
+func NewPaginationLinks(r *http.Request, strategy PageStrategy, page *Page) *Links {
+	first, prev, next, last := strategy.LinkParams(page)
+	return &Links{
+		Self:  NewLink(r.URL.String()),
+		First: newPageLink(r.URL, first),
+		Prev:  newPageLink(r.URL, prev),
+		Next:  newPageLink(r.URL, next),
+		Last:  newPageLink(r.URL, last),
+	}
+}
+
+// newPageLink returns a link to the given URL with its page parameters replaced by params.
+// It returns nil if params is nil.
+func newPageLink(u *url.URL, params map[string]string) *Link {
+	if params == nil {
+		return nil
+	}
+	values := u.Query()
+	for key := range values {
+		if inFamily(key, paramPage) {
+			delete(values, key)
+		}
+	}
+	for name, value := range params {
+		values.Set(pageParam(name), value)
+	}
+	link := *u
+	link.RawQuery = values.Encode()
+	return NewLink(link.String())
+}
+
+// NumberPagination is the page-based strategy using page[number] and page[size].
+type NumberPagination struct {
+	// DefaultSize is the page size used if page[size] is not provided
+	DefaultSize int
+	// MaxSize is the maximum page size a client can request, 0 means unlimited
+	MaxSize int
+}
+
+// NewNumberPagination returns a page-based strategy with the given default and maximum page sizes.
+func NewNumberPagination(defaultSize, maxSize int) *NumberPagination {
+	return &NumberPagination{DefaultSize: defaultSize, MaxSize: maxSize}
+}
+
+// ParsePage implements PageStrategy for page-based pagination.
+func (s *NumberPagination) ParsePage(params map[string]string) (*Page, *Error) {
+	if err := checkPageParams(params, pageNumber, pageSize); err != nil {
+		return nil, err
+	}
+	number, err := parsePageInt(params, pageNumber, firstPageNumber, firstPageNumber)
+	if err != nil {
+		return nil, err
+	}
+	size, err := parsePageSize(params, pageSize, s.DefaultSize, s.MaxSize)
+	if err != nil {
+		return nil, err
+	}
+	// The offset of the page must be representable, see Page.Bounds
+	if size > 0 && number-1 > maxPageInt/size {
+		return nil, ParameterError(fmt.Sprintf("'%s' is out of range", pageParam(pageNumber)), pageParam(pageNumber))
+	}
+	return &Page{Number: number, Size: size}, nil
+}
+
+// LinkParams implements PageStrategy for page-based pagination.
+func (s *NumberPagination) LinkParams(page *Page) (first, prev, next, last map[string]string) {
+	size := atLeastOne(page.Size)
+	params := func(number int) map[string]string {
+		return map[string]string{
+			pageNumber: strconv.Itoa(number),
+			pageSize:   strconv.Itoa(size),
+		}
+	}
+	first = params(firstPageNumber)
+	if page.Number > firstPageNumber {
+		prev = params(page.Number - 1)
+	}
+	if page.Total > 0 {
+		lastNumber := (page.Total + size - 1) / size
+		if page.Number < lastNumber {
+			next = params(page.Number + 1)
+		}
+		last = params(lastNumber)
+	}
+	return first, prev, next, last
+}
+
+// OffsetPagination is the offset-based strategy using page[offset] and page[limit].
+type OffsetPagination struct {
+	// DefaultLimit is the limit used if page[limit] is not provided
+	DefaultLimit int
+	// MaxLimit is the maximum limit a client can request, 0 means unlimited
+	MaxLimit int
+}
+
+// NewOffsetPagination returns an offset-based strategy with the given default and maximum limits.
+func NewOffsetPagination(defaultLimit, maxLimit int) *OffsetPagination {
+	return &OffsetPagination{DefaultLimit: defaultLimit, MaxLimit: maxLimit}
+}
+
+// ParsePage implements PageStrategy for offset-based pagination.
+func (s *OffsetPagination) ParsePage(params map[string]string) (*Page, *Error) {
+	if err := checkPageParams(params, pageOffset, pageLimit); err != nil {
+		return nil, err
+	}
+	offset, err := parsePageInt(params, pageOffset, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	limit, err := parsePageSize(params, pageLimit, s.DefaultLimit, s.MaxLimit)
+	if err != nil {
+		return nil, err
+	}
+	// The end of the page must be representable, see Page.Window
+	if offset > maxPageInt-limit {
+		return nil, ParameterError(fmt.Sprintf("'%s' is out of range", pageParam(pageOffset)), pageParam(pageOffset))
+	}
+	return &Page{Offset: offset, Limit: limit}, nil
+}
+
+// LinkParams implements PageStrategy for offset-based pagination.
+func (s *OffsetPagination) LinkParams(page *Page) (first, prev, next, last map[string]string) {
+	limit := atLeastOne(page.Limit)
+	params := func(offset int) map[string]string {
+		return map[string]string{
+			pageOffset: strconv.Itoa(offset),
+			pageLimit:  strconv.Itoa(limit),
+		}
+	}
+	first = params(0)
+	if page.Offset > 0 {
+		offset := page.Offset - limit
+		if offset < 0 {
+			offset = 0
+		}
+		prev = params(offset)
+	}
+	if page.Total > 0 {
+		if page.Offset < page.Total-limit {
+			next = params(page.Offset + limit)
+		}
+		last = params((page.Total - 1) / limit * limit)
+	}
+	return first, prev, next, last
+}
+
+// CursorPagination is the cursor-based strategy using page[cursor] and page[size].
+type CursorPagination struct {
+	// DefaultSize is the page size used if page[size] is not provided
+	DefaultSize int
+	// MaxSize is the maximum page size a client can request, 0 means unlimited
+	MaxSize int
+}
+
+// NewCursorPagination returns a cursor-based strategy with the given default and maximum page sizes.
+func NewCursorPagination(defaultSize, maxSize int) *CursorPagination {
+	return &CursorPagination{DefaultSize: defaultSize, MaxSize: maxSize}
+}
+
+// ParsePage implements PageStrategy for cursor-based pagination.
+func (s *CursorPagination) ParsePage(params map[string]string) (*Page, *Error) {
+	if err := checkPageParams(params, pageCursor, pageSize); err != nil {
+		return nil, err
+	}
+	size, err := parsePageSize(params, pageSize, s.DefaultSize, s.MaxSize)
+	if err != nil {
+		return nil, err
+	}
+	return &Page{Cursor: params[pageCursor], Size: size}, nil
+}
+
+// LinkParams implements PageStrategy for cursor-based pagination.
+// The last link is always omitted as cursors cannot address the last page.
+func (s *CursorPagination) LinkParams(page *Page) (first, prev, next, last map[string]string) {
+	params := func(cursor string) map[string]string {
+		result := map[string]string{}
+		if cursor != "" {
+			result[pageCursor] = cursor
+		}
+		if page.Size > 0 {
+			result[pageSize] = strconv.Itoa(page.Size)
+		}
+		return result
+	}
+	first = params("")
+	if page.PrevCursor != "" {
+		prev = params(page.PrevCursor)
+	}
+	if page.NextCursor != "" {
+		next = params(page.NextCursor)
+	}
+	return first, prev, next, nil
+}
+
+// atLeastOne returns the page size or limit, or 1 if it is not set, so that links
+// always carry a size or limit that ParsePage accepts.
+func atLeastOne(n int) int {
+	if n < 1 {
+		return 1
+	}
+	return n
+}
+
+// pageParam returns the query parameter name of the given page member.
+func pageParam(name string) string {
+	return fmt.Sprintf("%s[%s]", paramPage, name)
+}
+
+// checkPageParams returns a ParameterError if a page parameter is not supported by the strategy.
+func checkPageParams(params map[string]string, supported ...string) *Error {
+	for _, name := range sortedKeys(params) {
+		if !containsString(supported, name) {
+			return ParameterError(fmt.Sprintf("Pagination parameter '%s' is not supported", pageParam(name)), pageParam(name))
+		}
+	}
+	return nil
+}
+
+// parsePageInt parses the given page parameter as an integer greater or equal to min.
+// It returns def if the parameter is not provided.
+func parsePageInt(params map[string]string, name string, def, min int) (int, *Error) {
+	value, ok := params[name]
+	if !ok {
+		return def, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < min {
+		return 0, ParameterError(fmt.Sprintf("'%s' must be an integer greater or equal to %d", pageParam(name), min), pageParam(name))
+	}
+	return n, nil
+}
+
+// parsePageSize parses the given page size parameter and checks it against the maximum size.
+// A default size lower than 1 falls back to 1, as a page always holds at least one item.
+func parsePageSize(params map[string]string, name string, def, max int) (int, *Error) {
+	if def < 1 {
+		def = 1
+	}
+	size, err := parsePageInt(params, name, def, 1)
+	if err != nil {
+		return 0, err
+	}
+	if max != unlimitedPageMax && size > max {
+		return 0, ParameterError(fmt.Sprintf("'%s' must not exceed %d", pageParam(name), max), pageParam(name))
+	}
+	return size, nil
+}
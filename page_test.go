@@ -0,0 +1,177 @@
+package jsh
+
+import (
+	"net/http"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestPage(t *testing.T) {
+
+	Convey("Page Tests", t, func() {
+
+		parse := func(rawQuery string, strategy PageStrategy) (*Page, *Error) {
+			req, err := http.NewRequest("GET", "/articles?"+rawQuery, nil)
+			So(err, ShouldBeNil)
+			return ParsePage(req, strategy)
+		}
+
+		Convey("->NumberPagination", func() {
+			strategy := NewNumberPagination(10, 50)
+
+			Convey("should parse page number and size", func() {
+				page, err := parse("page[number]=3&page[size]=20", strategy)
+				So(err, ShouldBeNil)
+				So(page, ShouldResemble, &Page{Number: 3, Size: 20})
+
+				offset, limit := page.Bounds()
+				So(offset, ShouldEqual, 40)
+				So(limit, ShouldEqual, 20)
+			})
+
+			Convey("should use defaults", func() {
+				page, err := parse("", strategy)
+				So(err, ShouldBeNil)
+				So(page, ShouldResemble, &Page{Number: 1, Size: 10})
+			})
+
+			Convey("should reject invalid input", func() {
+				for _, q := range []string{"page[number]=0", "page[number]=a", "page[size]=51", "page[size]=0", "page[offset]=1", "page=1", "page[size][max]=1"} {
+					_, err := parse(q, strategy)
+					So(err, ShouldNotBeNil)
+					So(err.Status, ShouldEqual, http.StatusBadRequest)
+					So(err.Source.Parameter, ShouldStartWith, "page")
+				}
+			})
+
+			Convey("should reject page numbers whose offset overflows", func() {
+				_, err := parse("page[number]=2305843009213693954&page[size]=8", strategy)
+				So(err, ShouldNotBeNil)
+				So(err.Status, ShouldEqual, http.StatusBadRequest)
+				So(err.Source.Parameter, ShouldEqual, "page[number]")
+			})
+
+			Convey("should fall back to a size of one without a default size", func() {
+				page, err := parse("page[number]=2", &NumberPagination{MaxSize: 100})
+				So(err, ShouldBeNil)
+				So(page, ShouldResemble, &Page{Number: 2, Size: 1})
+			})
+		})
+
+		Convey("->OffsetPagination", func() {
+			strategy := NewOffsetPagination(10, 0)
+
+			Convey("should parse page offset and limit", func() {
+				page, err := parse("page[offset]=5&page[limit]=1000", strategy)
+				So(err, ShouldBeNil)
+				So(page, ShouldResemble, &Page{Offset: 5, Limit: 1000})
+			})
+
+			Convey("should reject invalid input", func() {
+				for _, q := range []string{"page[offset]=-1", "page[offset]=9223372036854775800"} {
+					_, err := parse(q, strategy)
+					So(err, ShouldNotBeNil)
+					So(err.Status, ShouldEqual, http.StatusBadRequest)
+					So(err.Source.Parameter, ShouldEqual, "page[offset]")
+				}
+			})
+		})
+
+		Convey("->CursorPagination", func() {
+			strategy := NewCursorPagination(10, 100)
+
+			Convey("should parse page cursor and size", func() {
+				page, err := parse("page[cursor]=abc&page[size]=5", strategy)
+				So(err, ShouldBeNil)
+				So(page, ShouldResemble, &Page{Cursor: "abc", Size: 5})
+			})
+
+			Convey("should reject unsupported parameters", func() {
+				_, err := parse("page[number]=2", strategy)
+				So(err, ShouldNotBeNil)
+				So(err.Source.Parameter, ShouldEqual, "page[number]")
+			})
+		})
+
+		Convey("->Window()", func() {
+			list := List{{ID: "1"}, {ID: "2"}, {ID: "3"}}
+
+			So((&Page{Number: 2, Size: 2}).Window(list), ShouldResemble, List{{ID: "3"}})
+			So((&Page{Offset: 1, Limit: 1}).Window(list), ShouldResemble, List{{ID: "2"}})
+			So((&Page{Number: 3, Size: 2}).Window(list), ShouldBeEmpty)
+			So((&Page{Number: 2305843009213693954, Size: 8}).Window(list), ShouldBeEmpty)
+			So((&Page{Offset: -1, Limit: 1}).Window(list), ShouldResemble, List{{ID: "1"}})
+			So((&Page{Offset: 1, Limit: maxPageInt}).Window(list), ShouldResemble, List{{ID: "2"}, {ID: "3"}})
+		})
+	})
+}
+
+func TestPaginationLinks(t *testing.T) {
+
+	Convey("Pagination Links Tests", t, func() {
+
+		links := func(rawURL string, strategy PageStrategy, page *Page) *Links {
+			req, err := http.NewRequest("GET", rawURL, nil)
+			So(err, ShouldBeNil)
+			return NewPaginationLinks(req, strategy, page)
+		}
+
+		Convey("->NumberPagination", func() {
+			strategy := NewNumberPagination(10, 0)
+
+			Convey("should build every link and keep other parameters", func() {
+				l := links("/articles?sort=-created&page[number]=2&page[size]=10", strategy, &Page{Number: 2, Size: 10, Total: 35})
+				So(l.Self.HREF, ShouldEqual, "/articles?sort=-created&page[number]=2&page[size]=10")
+				So(l.First.HREF, ShouldEqual, "/articles?page%5Bnumber%5D=1&page%5Bsize%5D=10&sort=-created")
+				So(l.Prev.HREF, ShouldEqual, "/articles?page%5Bnumber%5D=1&page%5Bsize%5D=10&sort=-created")
+				So(l.Next.HREF, ShouldEqual, "/articles?page%5Bnumber%5D=3&page%5Bsize%5D=10&sort=-created")
+				So(l.Last.HREF, ShouldEqual, "/articles?page%5Bnumber%5D=4&page%5Bsize%5D=10&sort=-created")
+			})
+
+			Convey("should omit links that do not apply", func() {
+				l := links("/articles", strategy, &Page{Number: 1, Size: 10})
+				So(l.First, ShouldNotBeNil)
+				So(l.Prev, ShouldBeNil)
+				So(l.Next, ShouldBeNil)
+				So(l.Last, ShouldBeNil)
+			})
+
+			Convey("should use a size of at least one", func() {
+				l := links("/articles", strategy, &Page{Number: 2, Total: 3})
+				So(l.First.HREF, ShouldEqual, "/articles?page%5Bnumber%5D=1&page%5Bsize%5D=1")
+				So(l.Prev.HREF, ShouldEqual, "/articles?page%5Bnumber%5D=1&page%5Bsize%5D=1")
+				So(l.Next.HREF, ShouldEqual, "/articles?page%5Bnumber%5D=3&page%5Bsize%5D=1")
+				So(l.Last.HREF, ShouldEqual, "/articles?page%5Bnumber%5D=3&page%5Bsize%5D=1")
+			})
+		})
+
+		Convey("->OffsetPagination", func() {
+			strategy := NewOffsetPagination(10, 0)
+
+			Convey("should build every link", func() {
+				l := links("/articles", strategy, &Page{Offset: 5, Limit: 10, Total: 25})
+				So(l.First.HREF, ShouldEqual, "/articles?page%5Blimit%5D=10&page%5Boffset%5D=0")
+				So(l.Prev.HREF, ShouldEqual, "/articles?page%5Blimit%5D=10&page%5Boffset%5D=0")
+				So(l.Next.HREF, ShouldEqual, "/articles?page%5Blimit%5D=10&page%5Boffset%5D=15")
+				So(l.Last.HREF, ShouldEqual, "/articles?page%5Blimit%5D=10&page%5Boffset%5D=20")
+			})
+
+			Convey("should use a limit of at least one", func() {
+				l := links("/articles", strategy, &Page{Offset: 1, Total: 3})
+				So(l.First.HREF, ShouldEqual, "/articles?page%5Blimit%5D=1&page%5Boffset%5D=0")
+				So(l.Prev.HREF, ShouldEqual, "/articles?page%5Blimit%5D=1&page%5Boffset%5D=0")
+				So(l.Next.HREF, ShouldEqual, "/articles?page%5Blimit%5D=1&page%5Boffset%5D=2")
+				So(l.Last.HREF, ShouldEqual, "/articles?page%5Blimit%5D=1&page%5Boffset%5D=2")
+			})
+		})
+
+		Convey("->CursorPagination", func() {
+			l := links("/articles?page[cursor]=b", NewCursorPagination(10, 0), &Page{Cursor: "b", Size: 10, PrevCursor: "a", NextCursor: "c"})
+			So(l.First.HREF, ShouldEqual, "/articles?page%5Bsize%5D=10")
+			So(l.Prev.HREF, ShouldEqual, "/articles?page%5Bcursor%5D=a&page%5Bsize%5D=10")
+			So(l.Next.HREF, ShouldEqual, "/articles?page%5Bcursor%5D=c&page%5Bsize%5D=10")
+			So(l.Last, ShouldBeNil)
+		})
+	})
+}
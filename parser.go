@@ -53,8 +53,11 @@ func ParseObject(r *http.Request) (*Object, *Error) {
 	}
 
 	object := document.First()
+	// A lid only stands for the ID of a resource created by the request
 	if r.Method != "POST" && object.ID == "" {
-		return nil, InputError("Missing mandatory object attribute", "id")
+		err := InputError("Missing mandatory object attribute", "id")
+		err.Source.Pointer = "/data/id"
+		return nil, err
 	}
 
 	return object, nil
@@ -84,10 +87,10 @@ func ParseRelationship(r *http.Request) (*IDObject, *Error) {
 	}
 
 	object := document.First()
-	if object.ID == "" {
+	if !object.identified() {
 		return nil, InputError("Missing mandatory object attribute", "id")
 	}
-	return NewIDObject(object.Type, object.ID), nil
+	return object.ToIDObject(), nil
 }
 
 /*
@@ -102,7 +105,7 @@ func ParseRelationshipList(r *http.Request) (IDList, *Error) {
 
 	var list IDList
 	for _, object := range document.Data {
-		list = append(list, NewIDObject(object.Type, object.ID))
+		list = append(list, object.ToIDObject())
 	}
 
 	return list, nil
@@ -153,6 +156,32 @@ func (p *Parser) Document(payload io.ReadCloser, mode DocumentMode) (*Document,
 		return nil, BadRequestError("Invalid JSON Document", decodeErr.Error())
 	}
 
+	// Atomic operations documents replace primary data with operations
+	if mode == AtomicMode {
+		if err := validateAtomicHeaders(p.Headers); err != nil {
+			return nil, err
+		}
+		if err := document.validateOperations(); err != nil {
+			return nil, err
+		}
+		return document, nil
+	}
+
+	// Meta-only documents must have meta and cannot have primary data
+	if mode == MetaMode {
+		if document.HasData() {
+			return nil, BadRequestError("Invalid meta-only document", "Primary data is not allowed")
+		}
+		if document.Meta == nil {
+			return nil, TopLevelError("meta")
+		}
+		// Consistent with Meta.Validate, which rejects empty meta-only responses
+		if meta, ok := document.Meta.(map[string]interface{}); !ok || len(meta) == 0 {
+			return nil, BadRequestError("Invalid meta-only document", "Meta must be a non-empty object")
+		}
+		return document, nil
+	}
+
 	// If the document has data, validate against specification
 	if document.HasData() {
 		for _, object := range document.Data {
@@ -168,7 +197,7 @@ func (p *Parser) Document(payload io.ReadCloser, mode DocumentMode) (*Document,
 
 			// if we have a list, then all resource objects should have IDs, will
 			// cross the bridge of bulk creation if and when there is a use case
-			if len(document.Data) > 1 && object.ID == "" {
+			if len(document.Data) > 1 && !object.identifiedFor(p.Method) {
 				return nil, InputError("Object without ID present in list", "id")
 			}
 		}
@@ -187,16 +216,20 @@ func closeReader(reader io.ReadCloser) {
 	}
 }
 
+// validateHeaders validates the headers of a JSON API payload, see validateContentType.
 func validateHeaders(headers http.Header) *Error {
+	return validateContentType(headers)
+}
 
-	reqContentType := headers.Get("Content-Type")
-	if reqContentType != ContentType {
-		return SpecificationError(fmt.Sprintf(
-			"Expected Content-Type header to be %s, got: %s",
-			ContentType,
-			reqContentType,
+// validateAtomicHeaders checks that the Content-Type of the payload applies the
+// Atomic Operations extension.
+func validateAtomicHeaders(headers http.Header) *Error {
+	mediaType, err := ParseMediaType(headers.Get("Content-Type"))
+	if err != nil || !containsString(mediaType.Extensions(), AtomicExtension) {
+		return UnsupportedMediaTypeError(fmt.Sprintf(
+			"Content-Type header must apply the extension %s",
+			AtomicExtension,
 		))
 	}
-
 	return nil
 }
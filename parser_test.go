@@ -19,7 +19,7 @@ func TestParsing(t *testing.T) {
 
 			err := validateHeaders(req.Header)
 			So(err, ShouldNotBeNil)
-			So(err.Status, ShouldEqual, http.StatusNotAcceptable)
+			So(err.Status, ShouldEqual, http.StatusUnsupportedMediaType)
 		})
 
 		Convey("->ParseObject()", func() {
@@ -236,5 +236,40 @@ func TestParsing(t *testing.T) {
 				So(err.Source.Pointer, ShouldEqual, "/data/attributes/type")
 			})
 		})
+
+		Convey("->Document(MetaMode)", func() {
+
+			parse := func(body string) (*Document, *Error) {
+				req, reqErr := testRequest([]byte(body))
+				So(reqErr, ShouldBeNil)
+				return ParseDoc(req, MetaMode)
+			}
+
+			Convey("should parse a meta-only document", func() {
+				doc, err := parse(`{"meta": {"count": 2}}`)
+				So(err, ShouldBeNil)
+				So(doc.Mode, ShouldEqual, MetaMode)
+				So(doc.Meta, ShouldResemble, map[string]interface{}{"count": float64(2)})
+			})
+
+			Convey("should reject a document without meta or with data", func() {
+				_, err := parse(`{"jsonapi": {"version": "1.1"}}`)
+				So(err, ShouldNotBeNil)
+				So(err.Status, ShouldEqual, 422)
+
+				_, err = parse(`{"meta": {"count": 2}, "data": {"type": "user", "id": "1"}}`)
+				So(err, ShouldNotBeNil)
+				So(err.Status, ShouldEqual, http.StatusBadRequest)
+			})
+
+			Convey("should reject an empty meta like Meta.Validate", func() {
+				for _, body := range []string{`{"meta": {}}`, `{"meta": 2}`} {
+					_, err := parse(body)
+					So(err, ShouldNotBeNil)
+					So(err.Status, ShouldEqual, http.StatusBadRequest)
+				}
+				So(Meta{}.Validate(nil, true), ShouldNotBeNil)
+			})
+		})
 	})
 }
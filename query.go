@@ -0,0 +1,116 @@
+package jsh
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// queryParam is a query parameter belonging to a family of parameters such as
+// "fields[articles]" or "page[size]".
+type queryParam struct {
+	// Key is the raw query parameter name, e.g. "filter[age][gte]"
+	Key string
+	// Members contains the bracketed member names, e.g. ["age", "gte"]
+	Members []string
+	// Value is the query parameter value
+	Value string
+}
+
+// query returns the query values of the given request, or empty values if
+// the request has no URL.
+func query(r *http.Request) url.Values {
+	if r == nil || r.URL == nil {
+		return url.Values{}
+	}
+	return r.URL.Query()
+}
+
+// queryFamily returns the query parameters belonging to the given family, sorted by key.
+// It returns a ParameterError if one of the parameters is malformed or set more than once.
+func queryFamily(values url.Values, family string) ([]*queryParam, *Error) {
+	var keys []string
+	for key := range values {
+		if inFamily(key, family) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	var params []*queryParam
+	for _, key := range keys {
+		members, ok := parseMembers(key[len(family):])
+		if !ok || len(members) == 0 {
+			return nil, ParameterError(fmt.Sprintf("Malformed query parameter '%s'", key), key)
+		}
+		if len(values[key]) > 1 {
+			return nil, ParameterError(fmt.Sprintf("Query parameter '%s' must be set only once", key), key)
+		}
+		params = append(params, &queryParam{
+			Key:     key,
+			Members: members,
+			Value:   values.Get(key),
+		})
+	}
+	return params, nil
+}
+
+// inFamily returns true if the query parameter key belongs to the given family.
+func inFamily(key, family string) bool {
+	return key == family || strings.HasPrefix(key, family+"[")
+}
+
+// parseMembers parses a suffix of bracketed member names such as "[age][gte]".
+// It returns false if a bracket is unbalanced or a member name is empty.
+func parseMembers(s string) ([]string, bool) {
+	var members []string
+	for len(s) > 0 {
+		if s[0] != '[' {
+			return nil, false
+		}
+		end := strings.IndexByte(s, ']')
+		if end < 2 || strings.ContainsRune(s[1:end], '[') {
+			return nil, false
+		}
+		members = append(members, s[1:end])
+		s = s[end+1:]
+	}
+	return members, true
+}
+
+// splitList splits a comma separated query parameter value.
+// It returns false if one of the elements is empty.
+func splitList(value string) ([]string, bool) {
+	if value == "" {
+		return []string{}, true
+	}
+	list := strings.Split(value, ",")
+	for _, elem := range list {
+		if elem == "" {
+			return nil, false
+		}
+	}
+	return list, true
+}
+
+// containsString returns true if the list contains the given string.
+func containsString(list []string, s string) bool {
+	for _, elem := range list {
+		if elem == s {
+			return true
+		}
+	}
+	return false
+}
+
+// sortedKeys returns the keys of the map in lexical order.
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,115 @@
+package jsh
+
+import (
+	"bufio"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"runtime/debug"
+)
+
+// PanicReporter is called with the request and the ISE built from a recovered panic,
+// whose ISE attribute contains the panic value and the stack trace.
+type PanicReporter func(r *http.Request, err *Error)
+
+/*
+RecoveryHandler is an http.Handler middleware that recovers the panics of the next
+handler and responds with a JSON API 500 error document. The panic is reported
+to the reporter, or logged if the reporter is nil.
+
+	http.Handle("/articles", jsh.RecoveryHandler(articlesHandler, func(r *http.Request, err *jsh.Error) {
+		sentry.CaptureMessage(err.ISE)
+	}))
+
+If the next handler already started writing the response, the panic is only
+reported since the response cannot be replaced anymore. Otherwise, the headers set
+by the handler are discarded before sending the error document. As for net/http,
+http.ErrAbortHandler panics are not recovered.
+
+The writer passed to the next handler supports hijacking and HTTP/2 server push
+when the underlying writer does, and http.ResponseController for other features.
+*/
+func RecoveryHandler(next http.Handler, reporter PanicReporter) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writer := &recoveryWriter{ResponseWriter: w}
+		defer func() {
+			value := recover()
+			if value == nil {
+				return
+			}
+			if value == http.ErrAbortHandler {
+				panic(value)
+			}
+
+			err := ISE(fmt.Sprintf("panic: %v\n%s", value, debug.Stack()))
+			if cause, ok := value.(error); ok {
+				err.Cause = cause
+			}
+			if reporter != nil {
+				reporter(r, err)
+			} else {
+				log.Printf("Recovered from panic serving %s %s: %s", r.Method, r.URL, err.ISE)
+			}
+
+			if !writer.written {
+				header := w.Header()
+				for key := range header {
+					delete(header, key)
+				}
+				Send(w, r, err)
+			}
+		}()
+		next.ServeHTTP(writer, r)
+	})
+}
+
+// recoveryWriter records whether the response was started by the handler.
+type recoveryWriter struct {
+	http.ResponseWriter
+	written bool
+}
+
+// WriteHeader records that the response was started and forwards the status code.
+func (w *recoveryWriter) WriteHeader(status int) {
+	w.written = true
+	w.ResponseWriter.WriteHeader(status)
+}
+
+// Write records that the response was started and forwards the content.
+func (w *recoveryWriter) Write(content []byte) (int, error) {
+	w.written = true
+	return w.ResponseWriter.Write(content)
+}
+
+// Flush forwards to the underlying writer if it supports flushing.
+func (w *recoveryWriter) Flush() {
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		w.written = true
+		flusher.Flush()
+	}
+}
+
+// Hijack forwards to the underlying writer if it supports hijacking, e.g. for WebSocket
+// upgrades. A hijacked response is considered started.
+func (w *recoveryWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, http.ErrNotSupported
+	}
+	w.written = true
+	return hijacker.Hijack()
+}
+
+// Push forwards to the underlying writer if it supports HTTP/2 server push.
+func (w *recoveryWriter) Push(target string, opts *http.PushOptions) error {
+	if pusher, ok := w.ResponseWriter.(http.Pusher); ok {
+		return pusher.Push(target, opts)
+	}
+	return http.ErrNotSupported
+}
+
+// Unwrap returns the underlying writer, for use with http.ResponseController.
+func (w *recoveryWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,133 @@
+package jsh
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestRecovery(t *testing.T) {
+
+	Convey("Recovery Tests", t, func() {
+
+		req, err := http.NewRequest("GET", "/articles", nil)
+		So(err, ShouldBeNil)
+		writer := httptest.NewRecorder()
+
+		var reported *Error
+		reporter := func(r *http.Request, err *Error) {
+			reported = err
+		}
+
+		Convey("->RecoveryHandler()", func() {
+
+			Convey("should respond with a JSON API error document", func() {
+				cause := errors.New("nil map")
+				handler := RecoveryHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					panic(cause)
+				}), reporter)
+				handler.ServeHTTP(writer, req)
+
+				So(writer.Code, ShouldEqual, http.StatusInternalServerError)
+				So(writer.Header().Get("Content-Type"), ShouldEqual, ContentType)
+
+				var document struct {
+					Errors []*Error `json:"errors"`
+				}
+				So(json.Unmarshal(writer.Body.Bytes(), &document), ShouldBeNil)
+				So(document.Errors, ShouldHaveLength, 1)
+				So(document.Errors[0].Status, ShouldEqual, http.StatusInternalServerError)
+
+				So(reported, ShouldNotBeNil)
+				So(reported.ISE, ShouldStartWith, "panic: nil map\n")
+				So(reported.ISE, ShouldContainSubstring, "recovery_test.go")
+				So(errors.Is(reported, cause), ShouldBeTrue)
+			})
+
+			Convey("should not replace a started response", func() {
+				handler := RecoveryHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					w.WriteHeader(http.StatusAccepted)
+					panic("too late")
+				}), reporter)
+				handler.ServeHTTP(writer, req)
+
+				So(writer.Code, ShouldEqual, http.StatusAccepted)
+				So(writer.Body.Len(), ShouldEqual, 0)
+				So(reported, ShouldNotBeNil)
+			})
+
+			Convey("should not interfere without panic", func() {
+				handler := RecoveryHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					Send(w, r, Ok())
+				}), reporter)
+				handler.ServeHTTP(writer, req)
+
+				So(writer.Code, ShouldEqual, http.StatusOK)
+				So(reported, ShouldBeNil)
+			})
+
+			Convey("should discard the headers set before the panic", func() {
+				handler := RecoveryHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					w.Header().Set("Content-Length", "2")
+					w.Header().Set("X-Custom", "value")
+					panic("failure")
+				}), reporter)
+				handler.ServeHTTP(writer, req)
+
+				So(writer.Code, ShouldEqual, http.StatusInternalServerError)
+				So(writer.Header().Get("X-Custom"), ShouldBeEmpty)
+				So(writer.Header().Get("Content-Length"), ShouldEqual, strconv.Itoa(writer.Body.Len()))
+			})
+
+			Convey("should forward hijacking to the underlying writer", func() {
+				hijacker := &hijackRecorder{ResponseRecorder: writer}
+				handler := RecoveryHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					_, _, err := w.(http.Hijacker).Hijack()
+					So(err, ShouldBeNil)
+					panic("after upgrade")
+				}), reporter)
+				handler.ServeHTTP(hijacker, req)
+
+				So(hijacker.hijacked, ShouldBeTrue)
+				So(writer.Body.Len(), ShouldEqual, 0)
+				So(reported, ShouldNotBeNil)
+			})
+
+			Convey("should not support hijacking without underlying support", func() {
+				handler := RecoveryHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					_, _, err := w.(http.Hijacker).Hijack()
+					So(err, ShouldEqual, http.ErrNotSupported)
+					So(w.(interface{ Unwrap() http.ResponseWriter }).Unwrap(), ShouldEqual, writer)
+					So(errors.Is(http.NewResponseController(w).SetWriteDeadline(time.Now()), http.ErrNotSupported), ShouldBeTrue)
+				}), reporter)
+				handler.ServeHTTP(writer, req)
+			})
+
+			Convey("should not recover http.ErrAbortHandler", func() {
+				handler := RecoveryHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					panic(http.ErrAbortHandler)
+				}), reporter)
+				So(func() { handler.ServeHTTP(writer, req) }, ShouldPanicWith, http.ErrAbortHandler)
+			})
+		})
+	})
+}
+
+// hijackRecorder is a response recorder supporting hijacking.
+type hijackRecorder struct {
+	*httptest.ResponseRecorder
+	hijacked bool
+}
+
+func (r *hijackRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	r.hijacked = true
+	return nil, nil, nil
+}
@@ -17,10 +17,12 @@ type Relationship struct {
 	Meta  map[string]interface{} `json:"meta,omitempty"`
 }
 
-// IDObject identifies an individual resource.
+// IDObject identifies an individual resource. A resource created in the same request
+// document is identified by its local identifier (LID) instead of its ID.
 type IDObject struct {
 	Type string `json:"type" valid:"required"`
-	ID   string `json:"id" valid:"required"`
+	ID   string `json:"id,omitempty"`
+	LID  string `json:"lid,omitempty"`
 }
 
 // NewIDObject creates a new resource identifier object instance.
@@ -35,6 +37,7 @@ func NewIDObject(tp, id string) *IDObject {
 func (obj *IDObject) ToObject() *Object {
 	// We can safely ignore the error when attributes are nil
 	result, _ := NewObject(obj.ID, obj.Type, nil)
+	result.LID = obj.LID
 	return result
 }
 
@@ -47,9 +50,17 @@ func (obj *IDObject) Validate(r *http.Request, response bool) *Error {
 	if len(errlist) > 0 {
 		return errlist[0]
 	}
+	if obj != nil && !obj.identified() {
+		return SpecificationError("ID or LID must be set for resource identifier")
+	}
 	return nil
 }
 
+// identified returns true if the resource identifier has an ID or a local identifier.
+func (obj *IDObject) identified() bool {
+	return obj.ID != "" || obj.LID != ""
+}
+
 // IDList is a wrapper around a resource identifier slice that implements Sendable and Unmarshaler.
 // IDList also implements sort.Interface for []*IDObject based on the ID field.
 type IDList []*IDObject
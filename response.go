@@ -1,7 +1,6 @@
 package jsh
 
 import (
-	"encoding/json"
 	"fmt"
 	"net/http"
 	"strconv"
@@ -22,6 +21,9 @@ type Sendable interface {
 // Send is designed to always send a response, but will also return the last
 // error it encountered to help with debugging in the event of an Internal Server
 // Error.
+// The sparse fieldsets of the request (see ParseFieldsets) are applied to copies of the
+// resource objects of the payload before it is sent, and errors are localized in
+// the language negotiated from the Accept-Language header (see Messages).
 func Send(w http.ResponseWriter, r *http.Request, payload Sendable) *Error {
 	// Validate payload
 	var doc *Document
@@ -31,6 +33,10 @@ func Send(w http.ResponseWriter, r *http.Request, payload Sendable) *Error {
 		doc = Build(payload)
 		validationErr = doc.Validate(r, true)
 	}
+	if validationErr == nil {
+		// Apply the sparse fieldsets requested by the client
+		validationErr = pruneDocument(r, doc)
+	}
 	if validationErr != nil {
 		// Make the validation error the new response
 		doc = Build(validationErr)
@@ -41,7 +47,8 @@ func Send(w http.ResponseWriter, r *http.Request, payload Sendable) *Error {
 		}
 	}
 
-	err := sendDocument(w, doc)
+	localizeDocument(w, r, doc)
+	err := sendDocument(w, r, doc)
 	if err != nil {
 		return err
 	}
@@ -59,17 +66,74 @@ func Ok() *Document {
 	return doc
 }
 
+// Meta is a meta-only payload, sent as a top-level document without primary data:
+//
+//	jsh.Send(w, r, jsh.Meta{"count": 42})
+type Meta map[string]interface{}
+
+// Validate ensures that the meta-only payload is not empty.
+func (m Meta) Validate(r *http.Request, response bool) *Error {
+	if len(m) == 0 {
+		return ISE("Meta cannot be empty in a meta-only document")
+	}
+	return nil
+}
+
+// NewMetaDocument creates a meta-only document with a 200 OK status:
+//
+//	jsh.Send(w, r, jsh.NewMetaDocument(jsh.Meta{"status": "pending"}))
+func NewMetaDocument(meta Meta) *Document {
+	doc := New()
+	doc.Data = nil
+	if meta != nil {
+		doc.Meta = meta
+	}
+	doc.Status = http.StatusOK
+	doc.Mode = MetaMode
+
+	return doc
+}
+
+// pruneDocument removes the fields that were not requested by the client from the document.
+func pruneDocument(r *http.Request, document *Document) *Error {
+	if document.Mode == ErrorMode {
+		return nil
+	}
+	fields, err := ParseFieldsets(r, nil)
+	if err != nil {
+		return err
+	}
+	return document.Prune(fields)
+}
+
 // sendDocument marshals the document, sets the header and writes the result to the given writer.
-func sendDocument(w http.ResponseWriter, document *Document) *Error {
-	content, err := json.MarshalIndent(document, "", " ")
+// The extensions and profiles negotiated with the client are applied to the document, and
+// it is formatted with the encoder of the request (see RequestEncoder).
+func sendDocument(w http.ResponseWriter, r *http.Request, document *Document) *Error {
+	mediaType := responseMediaType(r, document)
+	content, err := RequestEncoder(r).Marshal(document)
 	if err != nil {
 		http.Error(w, DefaultErrorTitle, http.StatusInternalServerError)
 		return ISE(fmt.Sprintf("Unable to marshal JSON payload: %v", err))
 	}
 
-	w.Header().Add("Content-Type", ContentType)
+	w.Header().Add("Content-Type", mediaType.String())
 	w.Header().Set("Content-Length", strconv.Itoa(len(content)))
 	w.WriteHeader(document.Status)
 	w.Write(content)
 	return nil
 }
+
+// responseMediaType returns the media type of the response document, with the extensions
+// and profiles negotiated with the client, and applies them to the document.
+func responseMediaType(r *http.Request, document *Document) *MediaType {
+	mediaType := NegotiatedMediaType(r)
+	if document.Mode == AtomicMode {
+		mediaType.addExtension(AtomicExtension)
+	}
+	if document.JSONAPI != nil {
+		document.JSONAPI.Ext = mediaType.Extensions()
+		document.JSONAPI.Profile = mediaType.Profiles()
+	}
+	return mediaType
+}
@@ -57,6 +57,18 @@ func TestSend(t *testing.T) {
 			})
 		})
 
+		Convey("->Send(Meta)", func() {
+			err := Send(writer, request, Meta{"count": 2})
+			So(err, ShouldBeNil)
+			So(writer.Code, ShouldEqual, http.StatusOK)
+			So(writer.Body.String(), ShouldNotContainSubstring, `"data"`)
+
+			writer = httptest.NewRecorder()
+			err = Send(writer, request, Meta{})
+			So(err, ShouldNotBeNil)
+			So(writer.Code, ShouldEqual, http.StatusInternalServerError)
+		})
+
 		Convey("->Ok()", func() {
 			doc := Ok()
 			err := Send(writer, request, doc)
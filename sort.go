@@ -0,0 +1,136 @@
+package jsh
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+)
+
+const (
+	paramSort      = "sort"
+	sortDescPrefix = "-"
+)
+
+// SortField is a field of the sort query parameter along with its sort direction.
+type SortField struct {
+	Name       string
+	Descending bool
+}
+
+// String returns the sort field as formatted in the sort query parameter.
+func (f *SortField) String() string {
+	if f.Descending {
+		return sortDescPrefix + f.Name
+	}
+	return f.Name
+}
+
+/*
+Sort is the ordered list of fields requested through the sort query parameter:
+http://jsonapi.org/format/#fetching-sorting
+*/
+type Sort []*SortField
+
+/*
+ParseSort parses the sort query parameter of the request and validates that each
+requested field is part of the allowed fields.
+
+	GET /articles?sort=-created,title
+
+	sort, err := jsh.ParseSort(r, "created", "title")
+
+The allowed fields can also be read from the jsh tags of a model, see SortFields.
+A ParameterError is returned for malformed or unsupported fields.
+*/
+func ParseSort(r *http.Request, allowed ...string) (Sort, *Error) {
+	values := query(r)[paramSort]
+	if len(values) > 1 {
+		return nil, ParameterError("Query parameter 'sort' must be set only once", paramSort)
+	}
+
+	sort := Sort{}
+	if len(values) == 0 {
+		return sort, nil
+	}
+	names, ok := splitList(values[0])
+	if !ok {
+		return nil, ParameterError("Empty sort field", paramSort)
+	}
+	for _, name := range names {
+		field := &SortField{Name: name}
+		if strings.HasPrefix(name, sortDescPrefix) {
+			field.Name = name[len(sortDescPrefix):]
+			field.Descending = true
+		}
+		if field.Name == "" {
+			return nil, ParameterError("Empty sort field", paramSort)
+		}
+		if !containsString(allowed, field.Name) {
+			return nil, ParameterError(fmt.Sprintf("Sorting by '%s' is not supported", field.Name), paramSort)
+		}
+		if sort.Has(field.Name) {
+			return nil, ParameterError(fmt.Sprintf("Sort field '%s' is set more than once", field.Name), paramSort)
+		}
+		sort = append(sort, field)
+	}
+	return sort, nil
+}
+
+// Has returns true if the given field is part of the sort fields.
+func (s Sort) Has(name string) bool {
+	for _, field := range s {
+		if field.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// String returns the sort fields as formatted in the sort query parameter.
+func (s Sort) String() string {
+	names := make([]string, 0, len(s))
+	for _, field := range s {
+		names = append(names, field.String())
+	}
+	return strings.Join(names, tagSep)
+}
+
+/*
+SortFields returns the JSON names of the model fields that are tagged "sort".
+The model must be a struct or a pointer to a struct.
+
+	type Article struct {
+		Title   string    `json:"title"   jsh:"sort,create,update"`
+		Created time.Time `json:"created" jsh:"sort"`
+		Body    string    `json:"body"    jsh:"create,update"`
+	}
+
+	sort, err := jsh.ParseSort(r, jsh.SortFields(&Article{})...)
+*/
+func SortFields(model interface{}) []string {
+	rt := reflect.TypeOf(model)
+	if rt == nil {
+		return nil
+	}
+	if rt.Kind() == reflect.Ptr {
+		rt = rt.Elem()
+	}
+	if rt.Kind() != reflect.Struct {
+		return nil
+	}
+
+	var fields []string
+	for i := 0; i < rt.NumField(); i++ {
+		f := rt.Field(i)
+		if f.PkgPath != "" || decodeFieldTag(f.Tag.Get(tagNameJSH), tagSort) == nil {
+			continue
+		}
+		name := decodeJSONTag(f)
+		if name == tagIgnore {
+			continue
+		}
+		fields = append(fields, name)
+	}
+	return fields
+}
@@ -0,0 +1,71 @@
+package jsh
+
+import (
+	"net/http"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestSort(t *testing.T) {
+
+	Convey("Sort Tests", t, func() {
+
+		Convey("->ParseSort()", func() {
+
+			Convey("should parse ordered fields with direction", func() {
+				req, err := http.NewRequest("GET", "/articles?sort=-created,title", nil)
+				So(err, ShouldBeNil)
+
+				sort, parseErr := ParseSort(req, "created", "title")
+				So(parseErr, ShouldBeNil)
+				So(sort, ShouldResemble, Sort{
+					{Name: "created", Descending: true},
+					{Name: "title"},
+				})
+				So(sort.String(), ShouldEqual, "-created,title")
+			})
+
+			Convey("should return an empty sort without sort parameter", func() {
+				req, err := http.NewRequest("GET", "/articles", nil)
+				So(err, ShouldBeNil)
+
+				sort, parseErr := ParseSort(req, "created")
+				So(parseErr, ShouldBeNil)
+				So(sort, ShouldBeEmpty)
+			})
+
+			Convey("should reject unsupported and malformed fields", func() {
+				for _, q := range []string{"sort=body", "sort=-", "sort=title,,created", "sort=title,-title"} {
+					req, err := http.NewRequest("GET", "/articles?"+q, nil)
+					So(err, ShouldBeNil)
+
+					_, parseErr := ParseSort(req, "created", "title")
+					So(parseErr, ShouldNotBeNil)
+					So(parseErr.Status, ShouldEqual, http.StatusBadRequest)
+					So(parseErr.Source.Parameter, ShouldEqual, "sort")
+				}
+			})
+		})
+
+		Convey("->SortFields()", func() {
+
+			Convey("should return the fields tagged sort", func() {
+				model := struct {
+					Title   string `json:"title" jsh:"sort,create"`
+					Created int    `json:"created,omitempty" jsh:"sort"`
+					Body    string `json:"body" jsh:"create"`
+					Ignored string `json:"-" jsh:"sort"`
+				}{}
+
+				So(SortFields(&model), ShouldResemble, []string{"title", "created"})
+				So(SortFields(model), ShouldResemble, []string{"title", "created"})
+			})
+
+			Convey("should ignore non-struct types", func() {
+				So(SortFields("foo"), ShouldBeNil)
+				So(SortFields(nil), ShouldBeNil)
+			})
+		})
+	})
+}
@@ -0,0 +1,238 @@
+package jsh
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// ObjectStream provides the primary data of a streamed list document, see SendStream.
+type ObjectStream interface {
+	// Next returns the next resource object, or nil at the end of the stream. An error
+	// ends the stream as a failure.
+	Next() (*Object, *Error)
+}
+
+// StreamFunc is an iterator function used as an ObjectStream.
+type StreamFunc func() (*Object, *Error)
+
+// Next calls the iterator function.
+func (f StreamFunc) Next() (*Object, *Error) {
+	return f()
+}
+
+// StreamChannel returns a stream of the objects received on the channel, which ends
+// when the channel is closed.
+func StreamChannel(objects <-chan *Object) ObjectStream {
+	return StreamFunc(func() (*Object, *Error) {
+		return <-objects, nil
+	})
+}
+
+/*
+SendStream sends a list document whose primary data is read from the stream, writing
+the resource objects one by one instead of marshaling the whole document in memory.
+The document provides the other top-level members ("included", "links", "meta"...),
+which are written after the primary data. Its data must be empty.
+
+	rows, err := db.Query("SELECT id, name FROM users")
+	...
+	jsh.SendStream(w, r, jsh.New(), jsh.StreamFunc(func() (*jsh.Object, *jsh.Error) {
+		if !rows.Next() {
+			return nil, nil
+		}
+		var user User
+		if err := rows.Scan(&user.ID, &user.Name); err != nil {
+			return nil, jsh.ISE(err.Error())
+		}
+		return jsh.NewObject(user.ID, "users", user)
+	}))
+
+Every object is validated and pruned to the sparse fieldsets of the request as Send
+does, and the full linkage of the included resources is checked once the stream ends.
+The document is sent compact, without Content-Length, whatever the encoder of the request.
+
+Until the first object has been validated, a failure is sent as an error document
+as with Send. Once the response has started, the status and the beginning of the
+document cannot be replaced anymore: on a failure (validation, stream or write error)
+SendStream stops writing and leaves the document truncated, so that it is not valid
+JSON and clients cannot mistake it for a complete response. The failure is returned
+for logging; to also abort the connection, the handler can panic with
+http.ErrAbortHandler.
+*/
+func SendStream(w http.ResponseWriter, r *http.Request, document *Document, stream ObjectStream) *Error {
+	s := &streamSender{
+		w:       w,
+		r:       r,
+		primary: map[resourceKey]bool{},
+		linked:  map[resourceKey]bool{},
+	}
+
+	document, first, err := s.prepare(document, stream)
+	if err != nil {
+		Send(w, r, err)
+		return err
+	}
+	return s.send(document, first, stream)
+}
+
+// streamSender writes a streamed document.
+type streamSender struct {
+	w      http.ResponseWriter
+	r      *http.Request
+	fields Fieldsets
+	// mediaType is the negotiated media type, applied to the document before its tail is marshaled
+	mediaType *MediaType
+	// tail is the JSON of the top-level members written after the primary data
+	tail []byte
+	// primary and linked are the keys of the primary resources and of the resources
+	// they link, to check the included resources at the end of the stream
+	primary map[resourceKey]bool
+	linked  map[resourceKey]bool
+}
+
+// prepare validates the document and reads the first object of the stream, so that
+// failures can still be sent as an error document. It returns the copy of the document
+// to send, the document of the caller is left untouched.
+func (s *streamSender) prepare(document *Document, stream ObjectStream) (*Document, *Object, *Error) {
+	switch {
+	case document == nil:
+		return nil, nil, ISE("Cannot stream a nil document")
+	case document.HasData():
+		return nil, nil, ISE("The data of a streamed document must be provided by the stream")
+	case document.HasErrors():
+		return nil, nil, ISE("Attempting to stream an error document")
+	}
+	sent := *document
+	if sent.Status == 0 {
+		sent.Status = http.StatusOK
+	}
+	if sent.Status < 100 || sent.Status > 600 {
+		return nil, nil, ISE("Response HTTP Status is outside of valid range")
+	}
+
+	fields, err := ParseFieldsets(s.r, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	s.fields = fields
+	included, err := s.fields.prunedList(sent.Included)
+	if err != nil {
+		return nil, nil, err
+	}
+	sent.Included = included
+	s.mediaType = responseMediaType(s.r, &sent)
+	if err := s.marshalTail(&sent); err != nil {
+		return nil, nil, err
+	}
+
+	first, err := s.next(stream)
+	if err != nil {
+		return nil, nil, err
+	}
+	if first == nil {
+		// The document has no primary data, the included resources can be checked now
+		if err := s.validateIncluded(sent.Included); err != nil {
+			return nil, nil, err
+		}
+	}
+	return &sent, first, nil
+}
+
+// marshalTail marshals the top-level members of the document other than data.
+func (s *streamSender) marshalTail(document *Document) *Error {
+	tail := *document
+	tail.Data = nil
+	tail.Mode = MetaMode
+	content, err := json.Marshal(&tail)
+	if err != nil {
+		return ISE(fmt.Sprintf("Unable to marshal JSON payload: %v", err))
+	}
+	s.tail = content
+	return nil
+}
+
+// next reads, validates and prunes the next object of the stream.
+func (s *streamSender) next(stream ObjectStream) (*Object, *Error) {
+	object, err := stream.Next()
+	if err != nil || object == nil {
+		return nil, err
+	}
+	if err := object.Validate(s.r, true); err != nil {
+		return nil, err
+	}
+	object, err = s.fields.pruned(object)
+	if err != nil {
+		return nil, err
+	}
+	s.primary[object.key()] = true
+	addLinkageKeys(s.linked, object)
+	return object, nil
+}
+
+// send writes the document, starting with the first object of the stream.
+func (s *streamSender) send(document *Document, first *Object, stream ObjectStream) *Error {
+	s.w.Header().Add("Content-Type", s.mediaType.String())
+	s.w.WriteHeader(document.Status)
+
+	if err := s.write([]byte(`{"data":[`)); err != nil {
+		return err
+	}
+	for object, i := first, 0; object != nil; i++ {
+		content, jsonErr := json.Marshal(object)
+		if jsonErr != nil {
+			return ISE(fmt.Sprintf("Unable to marshal JSON payload: %v", jsonErr))
+		}
+		if i > 0 {
+			content = append([]byte{','}, content...)
+		}
+		if err := s.write(content); err != nil {
+			return err
+		}
+
+		var err *Error
+		if object, err = s.next(stream); err != nil {
+			return err
+		}
+	}
+
+	if err := s.validateIncluded(document.Included); err != nil {
+		return err
+	}
+	// The tail is a JSON object, append its members to the document
+	content := []byte{']'}
+	if len(s.tail) > 2 {
+		content = append(append(content, ','), s.tail[1:]...)
+	} else {
+		content = append(content, '}')
+	}
+	return s.write(content)
+}
+
+// validateIncluded checks the included resources against the streamed primary data,
+// as Document.Validate does.
+func (s *streamSender) validateIncluded(included []*Object) *Error {
+	if len(s.primary) == 0 && len(included) > 0 {
+		return ISE("'included' should only be set for a response if 'data' is as well")
+	}
+	keys := map[resourceKey]bool{}
+	for _, object := range included {
+		key := object.key()
+		if s.primary[key] {
+			return ISE(fmt.Sprintf("Included resource %s duplicates primary data", key))
+		}
+		if keys[key] {
+			return ISE(fmt.Sprintf("Included resource %s is included more than once", key))
+		}
+		keys[key] = true
+	}
+	return validateLinkage(included, s.linked)
+}
+
+// write writes the content to the response.
+func (s *streamSender) write(content []byte) *Error {
+	if _, err := s.w.Write(content); err != nil {
+		return ISE(fmt.Sprintf("Unable to write streamed document: %v", err))
+	}
+	return nil
+}
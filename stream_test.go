@@ -0,0 +1,177 @@
+package jsh
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestStream(t *testing.T) {
+
+	Convey("Stream Tests", t, func() {
+
+		request := &http.Request{Method: "GET"}
+		writer := httptest.NewRecorder()
+
+		newUser := func(id string) *Object {
+			object, err := NewObject(id, "users", map[string]string{"name": "user" + id, "email": "user@example.com"})
+			So(err, ShouldBeNil)
+			object.AddRelationshipOne("group", NewIDObject("groups", "1"))
+			return object
+		}
+		group, err := NewObject("1", "groups", nil)
+		So(err, ShouldBeNil)
+
+		listStream := func(objects ...*Object) ObjectStream {
+			return StreamFunc(func() (*Object, *Error) {
+				if len(objects) == 0 {
+					return nil, nil
+				}
+				object := objects[0]
+				objects = objects[1:]
+				return object, nil
+			})
+		}
+
+		Convey("->SendStream()", func() {
+
+			Convey("should stream a list document", func() {
+				document := New()
+				document.Included = []*Object{group}
+				document.Meta = map[string]interface{}{"total": 2}
+
+				err := SendStream(writer, request, document, listStream(newUser("1"), newUser("2")))
+				So(err, ShouldBeNil)
+				So(writer.Code, ShouldEqual, http.StatusOK)
+				So(writer.Header().Get("Content-Type"), ShouldEqual, ContentType)
+				So(writer.Header().Get("Content-Length"), ShouldBeEmpty)
+
+				result := &Document{Mode: ListMode}
+				So(json.Unmarshal(writer.Body.Bytes(), result), ShouldBeNil)
+				So(result.Data, ShouldHaveLength, 2)
+				So(result.Data[1].ID, ShouldEqual, "2")
+				So(result.Included, ShouldHaveLength, 1)
+				So(result.Meta, ShouldResemble, map[string]interface{}{"total": float64(2)})
+				So(result.JSONAPI.Version, ShouldEqual, JSONAPIVersion)
+			})
+
+			Convey("should apply the negotiated extensions and profiles", func() {
+				testExt := "https://example.com/ext/stream"
+				testProfile := "https://example.com/profiles/stream"
+				RegisterExtension(testExt)
+				RegisterProfile(testProfile)
+				defer supportedExtensions.remove(testExt)
+				defer supportedProfiles.remove(testProfile)
+
+				req, reqErr := http.NewRequest("GET", "/users", nil)
+				So(reqErr, ShouldBeNil)
+				req.Header.Set("Accept", ContentType+`; ext="`+testExt+`"; profile="`+testProfile+`"`)
+
+				err := SendStream(writer, req, New(), listStream(newUser("1")))
+				So(err, ShouldBeNil)
+				So(writer.Header().Get("Content-Type"), ShouldEqual, ContentType+`; ext="`+testExt+`"; profile="`+testProfile+`"`)
+
+				result := &Document{Mode: ListMode}
+				So(json.Unmarshal(writer.Body.Bytes(), result), ShouldBeNil)
+				So(result.JSONAPI.Ext, ShouldResemble, []string{testExt})
+				So(result.JSONAPI.Profile, ShouldResemble, []string{testProfile})
+			})
+
+			Convey("should stream the objects of a channel", func() {
+				objects := make(chan *Object, 2)
+				objects <- newUser("1")
+				objects <- newUser("2")
+				close(objects)
+
+				document := &Document{Included: []*Object{group}}
+				So(SendStream(writer, request, document, StreamChannel(objects)), ShouldBeNil)
+
+				result := &Document{Mode: ListMode}
+				So(json.Unmarshal(writer.Body.Bytes(), result), ShouldBeNil)
+				So(result.Data, ShouldHaveLength, 2)
+			})
+
+			Convey("should stream an empty list", func() {
+				So(SendStream(writer, request, &Document{}, listStream()), ShouldBeNil)
+				So(writer.Body.String(), ShouldEqual, `{"data":[]}`)
+			})
+
+			Convey("should stream an empty list with empty included resources", func() {
+				So(SendStream(writer, request, &Document{Included: []*Object{}}, listStream()), ShouldBeNil)
+				So(writer.Code, ShouldEqual, http.StatusOK)
+				So(writer.Body.String(), ShouldEqual, `{"data":[]}`)
+			})
+
+			Convey("should not modify the document", func() {
+				request, _ := http.NewRequest("GET", "/users?fields[groups]=name", nil)
+				included := []*Object{group}
+				document := &Document{Included: included}
+
+				So(SendStream(writer, request, document, listStream(newUser("1"))), ShouldBeNil)
+				So(document.Included, ShouldHaveLength, 1)
+				So(document.Included[0], ShouldEqual, group)
+				So(included[0], ShouldEqual, group)
+				So(document.Status, ShouldEqual, 0)
+			})
+
+			Convey("should apply sparse fieldsets", func() {
+				request, _ := http.NewRequest("GET", "/users?fields[users]=name", nil)
+				So(SendStream(writer, request, &Document{}, listStream(newUser("1"))), ShouldBeNil)
+
+				result := &Document{Mode: ListMode}
+				So(json.Unmarshal(writer.Body.Bytes(), result), ShouldBeNil)
+				So(string(result.Data[0].Attributes), ShouldEqual, `{"name":"user1"}`)
+				So(result.Data[0].Relationships, ShouldBeEmpty)
+			})
+
+			Convey("should send an error document before the first byte", func() {
+				err := SendStream(writer, request, &Document{}, listStream(&Object{ID: "2"}))
+				So(err, ShouldNotBeNil)
+				So(writer.Code, ShouldEqual, http.StatusNotAcceptable)
+
+				result := &Document{}
+				So(json.Unmarshal(writer.Body.Bytes(), result), ShouldBeNil)
+				So(result.Errors, ShouldHaveLength, 1)
+			})
+
+			Convey("should reject documents with data", func() {
+				document := Build(newUser("1"))
+				err := SendStream(writer, request, document, listStream())
+				So(err, ShouldNotBeNil)
+				So(writer.Code, ShouldEqual, http.StatusInternalServerError)
+			})
+
+			Convey("should truncate the document on failure after the first byte", func() {
+				err := SendStream(writer, request, &Document{}, listStream(newUser("1"), &Object{ID: "2"}))
+				So(err, ShouldNotBeNil)
+				So(writer.Code, ShouldEqual, http.StatusOK)
+				So(json.Valid(writer.Body.Bytes()), ShouldBeFalse)
+
+				writer = httptest.NewRecorder()
+				failure := ISE("cursor closed")
+				stream := listStream(newUser("1"))
+				err = SendStream(writer, request, &Document{}, StreamFunc(func() (*Object, *Error) {
+					if object, _ := stream.Next(); object != nil {
+						return object, nil
+					}
+					return nil, failure
+				}))
+				So(err, ShouldEqual, failure)
+				So(json.Valid(writer.Body.Bytes()), ShouldBeFalse)
+			})
+
+			Convey("should check the linkage of included resources", func() {
+				document := &Document{Included: []*Object{group}}
+				user, err := NewObject("1", "users", nil)
+				So(err, ShouldBeNil)
+
+				err = SendStream(writer, request, document, listStream(user))
+				So(err, ShouldNotBeNil)
+				So(json.Valid(writer.Body.Bytes()), ShouldBeFalse)
+			})
+		})
+	})
+}
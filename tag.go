@@ -15,6 +15,8 @@ const (
 	tagToMany      = "many"
 	tagCreate      = "create"
 	tagUpdate      = "update"
+	tagSort        = "sort"
+	tagPrimary     = "primary"
 	optionSep      = "/"
 	optionRequired = "required"
 	fieldSep       = "/"
@@ -30,12 +32,11 @@ type tags map[string]*tagOptions
 
 // decodeJSONTag returns the first JSON tag of the given struct field. If there is none, the field name is returned.
 func decodeJSONTag(f reflect.StructField) string {
-	rawTags := f.Tag.Get(tagNameJSON)
-	tags := strings.SplitN(rawTags, tagSep, -1)
-	if len(tags) == 0 {
+	name := strings.SplitN(f.Tag.Get(tagNameJSON), tagSep, 2)[0]
+	if name == "" {
 		return f.Name
 	}
-	return tags[0]
+	return name
 }
 
 // decodeFieldTags decodes all JSH tags from the struct field to a tag struct.
@@ -56,6 +57,19 @@ func decodeFieldTags(rawTags string) tags {
 	return result
 }
 
+// decodePrimaryTag returns the resource type of a primary field tagged `jsh:"primary,<type>"`.
+// It returns false if the field is not tagged as primary.
+func decodePrimaryTag(rawTags string) (string, bool) {
+	options := strings.SplitN(rawTags, tagSep, -1)
+	if options[0] != tagPrimary {
+		return "", false
+	}
+	if len(options) < 2 {
+		return "", true
+	}
+	return options[1], true
+}
+
 // decodeFieldTag decodes the JSH tag from the struct field to a tag struct.
 // It returns nil if the tag was not found.
 func decodeFieldTag(tags, tagName string) *tagOptions {
@@ -0,0 +1,325 @@
+package jsh
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+/*
+UnmarshalDocument unmarshals the primary data of a compound document to the target,
+a pointer to a model or to a slice of models, and wires its relationships to the
+models of the primary data and included resources.
+
+Models follow the tag conventions of MarshalObject: the primary field tagged
+`jsh:"primary,<type>"` receives the resource ID, relationship fields tagged "one" or
+"many" receive the related models, and attributes are unmarshaled with encoding/json:
+
+	type Article struct {
+		ID       int                  `json:"-"     jsh:"primary,articles"`
+		Title    string               `json:"title"`
+		Author   *User                `json:"-"     jsh:"one"`
+		Comments []*Comment           `json:"-"     jsh:"many"`
+		Tags     map[string]*IDObject `json:"-"     jsh:"many"`
+	}
+
+	var articles []*Article
+	if err := jsh.UnmarshalDocument(document, &articles); err != nil {
+		return err
+	}
+
+Every resource of the document is unmarshaled to a single model per Go type, so that
+relationship cycles are wired to the same instances. Relationship fields of type
+*IDObject (or maps and slices of *IDObject) only receive the resource linkage.
+
+The linkage that matches no resource of the document cannot be set to a model field,
+an UnresolvedLinkageError is returned for each of them once the rest of the graph has
+been unmarshaled. The errors of an error document are returned as is.
+*/
+func UnmarshalDocument(document *Document, target interface{}) ErrorList {
+	if document.HasErrors() {
+		return document.Errors
+	}
+
+	rv := reflect.ValueOf(target)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return ErrorList{ISE(fmt.Sprintf("UnmarshalDocument expects a non-nil pointer, got %T", target))}
+	}
+
+	u := newDocumentUnmarshaler(document)
+	ev := rv.Elem()
+	if ev.Kind() == reflect.Slice {
+		slice := reflect.MakeSlice(ev.Type(), 0, len(document.Data))
+		for i, object := range document.Data {
+			model, err := u.model(object, ev.Type().Elem(), fmt.Sprintf("/data/%d", i))
+			if err != nil {
+				return ErrorList{err}
+			}
+			slice = reflect.Append(slice, model)
+		}
+		ev.Set(slice)
+	} else {
+		if len(document.Data) > 1 {
+			return ErrorList{ISE(fmt.Sprintf("Cannot unmarshal a list of %d resources to %T", len(document.Data), target))}
+		}
+		if len(document.Data) == 1 {
+			model, err := u.model(document.Data[0], ev.Type(), "/data")
+			if err != nil {
+				return ErrorList{err}
+			}
+			ev.Set(model)
+		}
+	}
+
+	if len(u.unresolved) > 0 {
+		return u.unresolved
+	}
+	return nil
+}
+
+// modelKey identifies the model of a resource, as the same resource can be unmarshaled
+// to several Go types.
+type modelKey struct {
+	resourceKey
+	reflect.Type
+}
+
+// documentUnmarshaler unmarshals the resources of a document, each of them once per Go type.
+type documentUnmarshaler struct {
+	resources  map[resourceKey]*Object
+	pointers   map[resourceKey]string
+	models     map[modelKey]reflect.Value
+	unresolved ErrorList
+}
+
+func newDocumentUnmarshaler(document *Document) *documentUnmarshaler {
+	u := &documentUnmarshaler{
+		resources: map[resourceKey]*Object{},
+		pointers:  map[resourceKey]string{},
+		models:    map[modelKey]reflect.Value{},
+	}
+	for i, object := range document.Data {
+		pointer := "/data"
+		if document.Mode == ListMode {
+			pointer = fmt.Sprintf("/data/%d", i)
+		}
+		u.index(object, pointer)
+	}
+	for i, object := range document.Included {
+		u.index(object, fmt.Sprintf("/included/%d", i))
+	}
+	return u
+}
+
+// index registers the resource object and its JSON pointer in the document.
+func (u *documentUnmarshaler) index(object *Object, pointer string) {
+	key := object.key()
+	if _, ok := u.resources[key]; !ok {
+		u.resources[key] = object
+		u.pointers[key] = pointer
+	}
+}
+
+// model returns the model of the resource object as a value of type t, a model
+// struct or a pointer to one.
+func (u *documentUnmarshaler) model(object *Object, t reflect.Type, pointer string) (reflect.Value, *Error) {
+	st := t
+	if st.Kind() == reflect.Ptr {
+		st = st.Elem()
+	}
+	if st.Kind() != reflect.Struct {
+		return reflect.Value{}, ISE(fmt.Sprintf("Cannot unmarshal a resource object to %v, must be a struct", t))
+	}
+
+	key := modelKey{object.key(), st}
+	model, ok := u.models[key]
+	if !ok {
+		model = reflect.New(st)
+		// Register the model before unmarshaling its relationships to handle cycles
+		u.models[key] = model
+		if err := u.unmarshal(object, model.Elem(), pointer); err != nil {
+			return reflect.Value{}, err
+		}
+	}
+
+	if t.Kind() == reflect.Ptr {
+		return model, nil
+	}
+	return model.Elem(), nil
+}
+
+// unmarshal sets the ID, attributes and relationships of the model from the resource object.
+func (u *documentUnmarshaler) unmarshal(object *Object, sv reflect.Value, pointer string) *Error {
+	resourceType, _, err := primaryField(sv)
+	if err != nil {
+		return err
+	}
+	if resourceType != object.Type {
+		err := ConflictError(object.Type, "")
+		err.Source = &ErrorSource{Pointer: pointer + "/type"}
+		return err
+	}
+
+	if len(object.Attributes) > 0 {
+		if jsonErr := json.Unmarshal(object.Attributes, sv.Addr().Interface()); jsonErr != nil {
+			err := BadRequestError(fmt.Sprintf("For type '%s' unable to unmarshal", object.Type), jsonErr.Error())
+			err.Source = &ErrorSource{Pointer: pointer + "/attributes"}
+			return err
+		}
+	}
+
+	for _, field := range modelFields(sv) {
+		rawTags := field.Tag.Get(tagNameJSH)
+		if _, primary := decodePrimaryTag(rawTags); primary {
+			if err := setModelID(field.value, object.ID); err != nil {
+				err.Source = &ErrorSource{Pointer: pointer + "/id"}
+				return err
+			}
+			continue
+		}
+
+		tags := decodeFieldTags(rawTags)
+		_, one := tags[tagToOne]
+		_, many := tags[tagToMany]
+		if !one && !many {
+			continue
+		}
+		name, rel := findRelationship(object, toLowerFirstRune(field.Name))
+		if rel == nil || rel.Data == nil {
+			continue
+		}
+		relPointer := fmt.Sprintf("%s/relationships/%s/data", pointer, name)
+		if err := u.relationship(many, rel.Data, field.value, relPointer); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// relationship sets the relationship field (v) of the model to the related models.
+func (u *documentUnmarshaler) relationship(many bool, linkage IDList, v reflect.Value, pointer string) *Error {
+	if !many {
+		if len(linkage) == 0 {
+			return nil
+		}
+		related, ok, err := u.related(linkage[0], v.Type(), pointer)
+		if err != nil || !ok {
+			return err
+		}
+		v.Set(related)
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Slice:
+		slice := reflect.MakeSlice(v.Type(), 0, len(linkage))
+		for i, data := range linkage {
+			related, ok, err := u.related(data, v.Type().Elem(), fmt.Sprintf("%s/%d", pointer, i))
+			if err != nil {
+				return err
+			}
+			if ok {
+				slice = reflect.Append(slice, related)
+			}
+		}
+		v.Set(slice)
+	case reflect.Map:
+		keyKind := v.Type().Key().Kind()
+		if keyKind != reflect.String && keyKind != reflect.Int {
+			return ISE("Invalid map key type for to-many relation, must be string or int")
+		}
+		m := reflect.MakeMapWithSize(v.Type(), len(linkage))
+		for i, data := range linkage {
+			itemPointer := fmt.Sprintf("%s/%d", pointer, i)
+			related, ok, err := u.related(data, v.Type().Elem(), itemPointer)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				continue
+			}
+			key := reflect.ValueOf(data.ID)
+			if keyKind == reflect.Int {
+				id, convErr := strconv.Atoi(data.ID)
+				if convErr != nil {
+					err := BadRequestError("Invalid resource ID", convErr.Error())
+					err.Source = &ErrorSource{Pointer: itemPointer + "/id"}
+					return err
+				}
+				key = reflect.ValueOf(id)
+			}
+			m.SetMapIndex(key, related)
+		}
+		v.Set(m)
+	default:
+		return ISE(fmt.Sprintf("Invalid field type %v for to-many relation, must be slice or map", v.Type()))
+	}
+	return nil
+}
+
+// related returns the value of type t for the resource linkage: a copy of the linkage
+// for *IDObject, or the model of the linked resource. It returns false if the linked
+// resource is not part of the document.
+func (u *documentUnmarshaler) related(linkage *IDObject, t reflect.Type, pointer string) (reflect.Value, bool, *Error) {
+	idObjectType := reflect.TypeOf(IDObject{})
+	switch {
+	case t == idObjectType:
+		return reflect.ValueOf(*linkage), true, nil
+	case t == reflect.PtrTo(idObjectType):
+		copied := *linkage
+		return reflect.ValueOf(&copied), true, nil
+	}
+
+	object, ok := u.resources[linkage.key()]
+	if !ok {
+		u.unresolved = append(u.unresolved, UnresolvedLinkageError(linkage, pointer))
+		return reflect.Value{}, false, nil
+	}
+	model, err := u.model(object, t, u.pointers[linkage.key()])
+	if err != nil {
+		return reflect.Value{}, false, err
+	}
+	return model, true, nil
+}
+
+// findRelationship returns the relationship of the object matching the field name,
+// as for ProcessCreate.
+func findRelationship(object *Object, name string) (string, *Relationship) {
+	if rel, ok := object.Relationships[name]; ok {
+		return name, rel
+	}
+	for relName, rel := range object.Relationships {
+		if strings.EqualFold(relName, name) {
+			return relName, rel
+		}
+	}
+	return name, nil
+}
+
+// setModelID sets the primary field (v) of the model to the resource ID.
+func setModelID(v reflect.Value, id string) *Error {
+	if id == "" {
+		return nil
+	}
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(id)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(id, 10, v.Type().Bits())
+		if err != nil {
+			return BadRequestError("Invalid resource ID", err.Error())
+		}
+		v.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		i, err := strconv.ParseUint(id, 10, v.Type().Bits())
+		if err != nil {
+			return BadRequestError("Invalid resource ID", err.Error())
+		}
+		v.SetUint(i)
+	default:
+		return ISE(fmt.Sprintf("Invalid primary field type %v, must be a string or an integer", v.Type()))
+	}
+	return nil
+}
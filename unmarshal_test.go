@@ -0,0 +1,115 @@
+package jsh
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestUnmarshal(t *testing.T) {
+
+	Convey("Unmarshal Tests", t, func() {
+
+		Convey("->UnmarshalDocument()", func() {
+
+			Convey("should unmarshal a marshaled graph", func() {
+				author := &testAuthor{ID: "9", Name: "Jon"}
+				article := &testArticle{
+					ID:     1,
+					Title:  "JSON API",
+					Author: author,
+					Editor: NewIDObject("people", "3"),
+					Tags:   map[string]*IDObject{"1": NewIDObject("tags", "1")},
+				}
+				author.Articles = []*testArticle{article, {ID: 2, Title: "Go"}}
+
+				object, included, err := MarshalObject(article)
+				So(err, ShouldBeNil)
+				doc, err := BuildCompound(object, included...)
+				So(err, ShouldBeNil)
+
+				var result *testArticle
+				So(UnmarshalDocument(doc, &result), ShouldBeNil)
+				So(result.ID, ShouldEqual, 1)
+				So(result.Title, ShouldEqual, "JSON API")
+				So(result.Editor, ShouldResemble, NewIDObject("people", "3"))
+				So(result.Tags, ShouldResemble, map[string]*IDObject{"1": NewIDObject("tags", "1")})
+				So(result.Author.Name, ShouldEqual, "Jon")
+				So(result.Author.Articles, ShouldHaveLength, 2)
+				So(result.Author.Articles[0], ShouldEqual, result)
+				So(result.Author.Articles[1].Title, ShouldEqual, "Go")
+			})
+
+			Convey("should unmarshal a list", func() {
+				doc := &Document{Mode: ListMode}
+				So(json.Unmarshal([]byte(`{
+					"data": [
+						{"type": "articles", "id": "1", "relationships": {"author": {"data": {"type": "people", "id": "9"}}}},
+						{"type": "articles", "id": "2", "relationships": {"author": {"data": {"type": "people", "id": "9"}}}}
+					],
+					"included": [{"type": "people", "id": "9", "attributes": {"name": "Jon"}}]
+				}`), doc), ShouldBeNil)
+
+				var articles []testArticle
+				So(UnmarshalDocument(doc, &articles), ShouldBeNil)
+				So(articles, ShouldHaveLength, 2)
+				So(articles[0].Author.Name, ShouldEqual, "Jon")
+				So(articles[1].Author, ShouldEqual, articles[0].Author)
+			})
+
+			Convey("should report unresolved linkage", func() {
+				doc := &Document{}
+				So(json.Unmarshal([]byte(`{
+					"data": {
+						"type": "people",
+						"id": "9",
+						"relationships": {"articles": {"data": [
+							{"type": "articles", "id": "1"},
+							{"type": "articles", "id": "2"}
+						]}}
+					},
+					"included": [{"type": "articles", "id": "2", "attributes": {"title": "Go"}}]
+				}`), doc), ShouldBeNil)
+
+				var author testAuthor
+				errs := UnmarshalDocument(doc, &author)
+				So(errs, ShouldHaveLength, 1)
+				So(errs[0].Status, ShouldEqual, 422)
+				So(errs[0].Code, ShouldEqual, CodeUnresolvedLinkage)
+				So(errs[0].Source.Pointer, ShouldEqual, "/data/relationships/articles/data/0")
+				So(errs[0].Meta, ShouldResemble, map[string]interface{}{"type": "articles", "id": "1"})
+
+				So(author.ID, ShouldEqual, "9")
+				So(author.Articles, ShouldHaveLength, 1)
+				So(author.Articles[0].Title, ShouldEqual, "Go")
+			})
+
+			Convey("should reject resources of another type", func() {
+				doc := &Document{Data: List{{Type: "people", ID: "9"}}}
+				var article testArticle
+				errs := UnmarshalDocument(doc, &article)
+				So(errs, ShouldHaveLength, 1)
+				So(errs[0].Status, ShouldEqual, http.StatusConflict)
+				So(errs[0].Source.Pointer, ShouldEqual, "/data/type")
+			})
+
+			Convey("should return the errors of an error document", func() {
+				doc := Build(NotFound("articles", "1"))
+				var article testArticle
+				errs := UnmarshalDocument(doc, &article)
+				So(errs, ShouldHaveLength, 1)
+				So(errs[0].Status, ShouldEqual, http.StatusNotFound)
+			})
+
+			Convey("should reject invalid targets", func() {
+				doc := &Document{Data: List{{Type: "articles", ID: "1"}}}
+				So(UnmarshalDocument(doc, testArticle{}), ShouldNotBeNil)
+
+				var name string
+				So(UnmarshalDocument(doc, &name), ShouldNotBeNil)
+			})
+		})
+	})
+}
@@ -98,14 +98,7 @@ func (v *Validator) validateStruct(path string, rv reflect.Value, j json.RawMess
 		_, many := tags[tagToMany]
 		if one || many {
 			// Remove existing field from the relationships map
-			var rel *Relationship
-			for name, r := range v.object.Relationships {
-				if strings.EqualFold(name, f.Name) {
-					rel = r
-					delete(v.object.Relationships, name)
-					break
-				}
-			}
+			rel := v.takeRelationship(f.Name)
 			// Validate relationship
 			hasValue, err := validateModelRelationship(p, many, rel, tags[v.action])
 			if err != nil {
@@ -126,19 +119,12 @@ func (v *Validator) validateStruct(path string, rv reflect.Value, j json.RawMess
 			continue
 		}
 		// Remove existing field from the provided attributes map
-		var jValue json.RawMessage
-		for name, value := range attrs {
-			if strings.EqualFold(name, p) {
-				jValue = value
-				delete(attrs, name)
-				break
-			}
-		}
+		jValue := takeAttribute(attrs, p)
 		// Validate field
 		if path != "" {
 			p = path + fieldSep + p
 		}
-		hasValue, err := validateModelField(p, fv, tags[v.action])
+		hasValue, err := validateModelField(p, isZero(fv), tags[v.action])
 		if err != nil {
 			errors = append(errors, err)
 		} else if hasValue {
@@ -150,6 +136,39 @@ func (v *Validator) validateStruct(path string, rv reflect.Value, j json.RawMess
 			}
 		}
 	}
+	errors = append(errors, v.unknownFields(path, attrs)...)
+	if errors != nil {
+		return nil, errors
+	}
+	return fields, nil
+}
+
+// takeRelationship removes the relationship of the given field from the object and returns it.
+func (v *Validator) takeRelationship(field string) *Relationship {
+	for name, rel := range v.object.Relationships {
+		if strings.EqualFold(name, field) {
+			delete(v.object.Relationships, name)
+			return rel
+		}
+	}
+	return nil
+}
+
+// takeAttribute removes the given attribute from the provided attributes and returns its value.
+func takeAttribute(attrs map[string]json.RawMessage, attribute string) json.RawMessage {
+	for name, value := range attrs {
+		if strings.EqualFold(name, attribute) {
+			delete(attrs, name)
+			return value
+		}
+	}
+	return nil
+}
+
+// unknownFields returns the errors for the remaining attributes and relationships,
+// which do not exist in the model.
+func (v *Validator) unknownFields(path string, attrs map[string]json.RawMessage) ErrorList {
+	var errors ErrorList
 	// Add errors for non-existent attributes
 	for name := range attrs {
 		if path != "" {
@@ -164,10 +183,7 @@ func (v *Validator) validateStruct(path string, rv reflect.Value, j json.RawMess
 		}
 		errors = append(errors, RelationshipError("Relationship does not exist", name))
 	}
-	if errors != nil {
-		return nil, errors
-	}
-	return fields, nil
+	return errors
 }
 
 // nestedResult recurses until the field type is not a map, slice, array, interface, pointer or struct.
@@ -343,9 +359,9 @@ func validateModelRelationship(name string, many bool, rel *Relationship, opts *
 
 // validateModelField validates that the value for the given field
 // is neither missing or forbidden according to jsh tags.
-func validateModelField(path string, v reflect.Value, opts *tagOptions) (bool, *Error) {
+func validateModelField(path string, zero bool, opts *tagOptions) (bool, *Error) {
 	// Check if attribute was not provided
-	if isZero(v) {
+	if zero {
 		if opts != nil && opts.required {
 			return false, InputError("Required attribute", toLowerFirstRune(path))
 		}